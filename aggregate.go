@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// Separator between competition and season in data filenames i.e; "EPL - 2011-12.csv"
+const competitionSeasonSeparator = " - "
+
+// Struct to store a season of a competition, along with the data file it is read from
+type Season struct {
+	Competition string
+	Name        string
+	Filename    string
+}
+
+// Struct to store all-time honours of a team across all seasons of a competition
+type AllTimeHonours struct {
+	Rank          int
+	Team          string
+	SeasonsPlayed int
+	Titles        int
+	BestFinish    int
+	WorstFinish   int
+	AverageFinish float64
+}
+
+// Struct to store finishing position of a team in a season
+type SeasonFinish struct {
+	Season      string
+	Position    int
+	Team        string
+	GamesPlayed int
+	Points      int
+}
+
+/*
+Method that gets slice of stringified elements of `AllTimeHonours` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `AllTimeHonours` struct to CSV file.
+*/
+func (obj AllTimeHonours) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.SeasonsPlayed))
+	values = append(values, strconv.Itoa(obj.Titles))
+	values = append(values, strconv.Itoa(obj.BestFinish))
+	values = append(values, strconv.Itoa(obj.WorstFinish))
+	values = append(values, fmt.Sprintf("%g", obj.AverageFinish))
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `SeasonFinish` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `SeasonFinish` struct to CSV file.
+*/
+func (obj SeasonFinish) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Season)
+	values = append(values, strconv.Itoa(obj.Position))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, strconv.Itoa(obj.Points))
+	return values
+}
+
+/*
+Gets `Season` from data filename, using the filename prefix as the competition.
+Eg: "EPL - 2011-12.csv" belongs to competition "EPL" and season "2011-12".
+Filenames without the separator are considered to be the only season of their own competition.
+*/
+func getSeasonFromFilename(filename string) Season {
+	filenameWithoutExt := removeExtension(filename)
+	idx := strings.LastIndex(filenameWithoutExt, competitionSeasonSeparator)
+	if idx == -1 {
+		return Season{Competition: filenameWithoutExt, Name: filenameWithoutExt, Filename: filename}
+	}
+	return Season{
+		Competition: filenameWithoutExt[:idx],
+		Name:        filenameWithoutExt[idx+len(competitionSeasonSeparator):],
+		Filename:    filename,
+	}
+}
+
+/*
+Reads manifest CSV file having columns "Competition, Season, Filename" in that order.
+Returns map of filename -> `Season`
+*/
+func readSeasonManifest(filepath string) map[string]Season {
+	csvfile, err := os.Open(filepath)
+	if err != nil {
+		log.Fatalln("Couldn't open the manifest file", err)
+	}
+	defer csvfile.Close()
+	r := csv.NewReader(csvfile)
+	lineCount := 0
+	mapSeasonByFilename := map[string]Season{}
+	for {
+		lineCount++
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if lineCount != 1 {
+			if len(record) < 3 {
+				log.Fatalln("Expected the columns 'Competition, Season, Filename' in manifest file at line " + strconv.Itoa(lineCount))
+			}
+			mapSeasonByFilename[record[2]] = Season{Competition: record[0], Name: record[1], Filename: record[2]}
+		}
+	}
+	return mapSeasonByFilename
+}
+
+/*
+Groups data filenames into competitions. Filenames listed in the manifest (if any) use the competition/season given
+there, while the rest are grouped by filename prefix. Returns map of competition -> slice of seasons sorted by name.
+*/
+func groupFilenamesByCompetition(filenames []string, manifestFilepath string) map[string][]Season {
+	mapSeasonByFilename := map[string]Season{}
+	if manifestFilepath != "" {
+		mapSeasonByFilename = readSeasonManifest(manifestFilepath)
+	}
+	mapSeasonsByCompetition := map[string][]Season{}
+	for _, filename := range filenames {
+		season, ok := mapSeasonByFilename[filename]
+		if !ok {
+			season = getSeasonFromFilename(filename)
+		}
+		mapSeasonsByCompetition[season.Competition] = append(mapSeasonsByCompetition[season.Competition], season)
+	}
+	for _, seasons := range mapSeasonsByCompetition {
+		sort.SliceStable(seasons, func(i, j int) bool {
+			return seasons[i].Name < seasons[j].Name
+		})
+	}
+	return mapSeasonsByCompetition
+}
+
+// Gets finishing positions of teams in a season, from ranked absolute stats of the season
+func getSeasonFinishes(season Season, sliceAbsStatsRanked []StatsAbs) []SeasonFinish {
+	sliceSeasonFinishes := []SeasonFinish{}
+	for _, obj := range sliceAbsStatsRanked {
+		tempObj := SeasonFinish{
+			Season:      season.Name,
+			Position:    obj.Rank,
+			Team:        obj.Team,
+			GamesPlayed: obj.GamesPlayed,
+			Points:      obj.Points,
+		}
+		sliceSeasonFinishes = append(sliceSeasonFinishes, tempObj)
+	}
+	return sliceSeasonFinishes
+}
+
+/*
+Gets slice of all-time honours from finishing positions of all seasons.
+Sorted by titles won, then best finish, then average finish.
+*/
+func getAllTimeHonours(sliceSeasonFinishes []SeasonFinish) []AllTimeHonours {
+	mapFinishesByTeam := map[string][]int{}
+	teams := []string{}
+	for _, obj := range sliceSeasonFinishes {
+		if _, ok := mapFinishesByTeam[obj.Team]; !ok {
+			teams = append(teams, obj.Team)
+		}
+		mapFinishesByTeam[obj.Team] = append(mapFinishesByTeam[obj.Team], obj.Position)
+	}
+	sliceHonours := []AllTimeHonours{}
+	for _, team := range teams {
+		positions := mapFinishesByTeam[team]
+		titles, best, worst, sum := 0, positions[0], positions[0], 0
+		for _, position := range positions {
+			if position == 1 {
+				titles++
+			}
+			if position < best {
+				best = position
+			}
+			if position > worst {
+				worst = position
+			}
+			sum += position
+		}
+		tempObj := AllTimeHonours{
+			Team:          team,
+			SeasonsPlayed: len(positions),
+			Titles:        titles,
+			BestFinish:    best,
+			WorstFinish:   worst,
+			AverageFinish: round(float64(sum)/float64(len(positions)), 2),
+		}
+		sliceHonours = append(sliceHonours, tempObj)
+	}
+	sort.SliceStable(sliceHonours, func(i, j int) bool {
+		if sliceHonours[i].Titles != sliceHonours[j].Titles {
+			return sliceHonours[i].Titles > sliceHonours[j].Titles
+		}
+		if sliceHonours[i].BestFinish != sliceHonours[j].BestFinish {
+			return sliceHonours[i].BestFinish < sliceHonours[j].BestFinish
+		}
+		return sliceHonours[i].AverageFinish < sliceHonours[j].AverageFinish
+	})
+	for idx := range sliceHonours {
+		sliceHonours[idx].Rank = idx + 1
+	}
+	return sliceHonours
+}
+
+// Saves slice having objects of `AllTimeHonours` struct to CSV file
+func saveAllTimeHonoursToCsv(sliceData []AllTimeHonours, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&AllTimeHonours{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `SeasonFinish` struct to CSV file
+func saveSeasonFinishesToCsv(sliceData []SeasonFinish, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&SeasonFinish{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+/*
+Executes aggregation pipeline for every competition having more than one season, and stores all-time results.
+Saves all-time absolute/normalized tables (over the matches of all seasons combined), all-time honours
+(seasons played, titles, best/worst/average finish) and per-season finishing positions of every team.
+*/
+func executeAggregationPipeline(filenames []string, options PipelineOptions) {
+	mapSeasonsByCompetition := groupFilenamesByCompetition(filenames, options.Manifest)
+	competitions := []string{}
+	for competition := range mapSeasonsByCompetition {
+		competitions = append(competitions, competition)
+	}
+	sort.Strings(competitions)
+	for _, competition := range competitions {
+		seasons := mapSeasonsByCompetition[competition]
+		if len(seasons) < 2 {
+			continue
+		}
+		allTimeRecords := []RawData{}
+		sliceSeasonFinishes := []SeasonFinish{}
+		numSeasonsComputed := 0
+		for _, season := range seasons {
+			rawRecords := loadRawRecords(pathDataFolder+"/"+season.Filename, options)
+			if isHomeSameAsAway(rawRecords) {
+				fmt.Println("Skipping season '" + season.Name + "' of '" + competition + "' due to incorrect team-names")
+				continue
+			}
+			sliceAbsStats := getAbsoluteStats(rawRecords, options.ScoringRules)
+			sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
+			sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
+			sliceSeasonFinishes = append(sliceSeasonFinishes, getSeasonFinishes(season, sliceAbsStats)...)
+			allTimeRecords = append(allTimeRecords, rawRecords...)
+			numSeasonsComputed++
+		}
+		if numSeasonsComputed == 0 {
+			continue
+		}
+		sliceAbsStats := getAbsoluteStats(allTimeRecords, options.ScoringRules)
+		sliceNormStats := getRankedNormStats(sliceAbsStats, options)
+		sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
+		sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
+		sliceHonours := getAllTimeHonours(sliceSeasonFinishes)
+		// Save results
+		prefix := pathResultsFolder + "/" + competition + " - All-Time"
+		saveAbsToCsv(sliceAbsStats, prefix+" - Teams - Absolute Stats.csv")
+		saveNormToCsv(sliceNormStats, prefix+" - Teams - Normalized Stats.csv")
+		saveAllTimeHonoursToCsv(sliceHonours, prefix+" - Honours.csv")
+		saveSeasonFinishesToCsv(sliceSeasonFinishes, prefix+" - Finishing Positions.csv")
+		fmt.Println("Computed all-time stats for '" + competition + "' over " + strconv.Itoa(numSeasonsComputed) + " seasons")
+	}
+}
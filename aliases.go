@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/structs"
+)
+
+// Default path to alias file. It is optional, and ignored if it doesn't exist
+const pathAliasesFile = "aliases.csv"
+
+// Folding of accented (and other special) Latin letters to their closest ASCII equivalent
+var mapFoldedRunes = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Struct to store a pair of suspiciously similar team names, for curating the alias file
+type AliasSuggestion struct {
+	Name           string
+	SimilarTo      string
+	Reason         string
+	EditDistance   int
+	NameGames      int
+	SimilarToGames int
+}
+
+/*
+Method that gets slice of stringified elements of `AliasSuggestion` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `AliasSuggestion` struct to CSV file.
+*/
+func (obj AliasSuggestion) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Name)
+	values = append(values, obj.SimilarTo)
+	values = append(values, obj.Reason)
+	values = append(values, strconv.Itoa(obj.EditDistance))
+	values = append(values, strconv.Itoa(obj.NameGames))
+	values = append(values, strconv.Itoa(obj.SimilarToGames))
+	return values
+}
+
+/*
+Reads alias CSV file having columns "Alias, Canonical" in that order. Returns map of alias -> canonical name.
+Returns empty map if the file doesn't exist.
+*/
+func readAliases(filepath string) map[string]string {
+	mapCanonicalByAlias := map[string]string{}
+	if filepath == "" {
+		return mapCanonicalByAlias
+	}
+	csvfile, err := os.Open(filepath)
+	if os.IsNotExist(err) {
+		return mapCanonicalByAlias
+	}
+	if err != nil {
+		log.Fatalln("Couldn't open the alias file", err)
+	}
+	defer csvfile.Close()
+	r := csv.NewReader(csvfile)
+	lineCount := 0
+	for {
+		lineCount++
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if lineCount != 1 {
+			if len(record) < 2 {
+				log.Fatalln("Expected the columns 'Alias, Canonical' in alias file '" + filepath + "' at line " + strconv.Itoa(lineCount))
+			}
+			mapCanonicalByAlias[record[0]] = record[1]
+		}
+	}
+	return mapCanonicalByAlias
+}
+
+// Replaces team names found in the map of aliases with their canonical names
+func canonicaliseTeamNames(records []RawData, mapCanonicalByAlias map[string]string) []RawData {
+	recordsCanonicalised := []RawData{}
+	for _, record := range records {
+		if canonical, ok := mapCanonicalByAlias[record.HomeTeam]; ok {
+			record.HomeTeam = canonical
+		}
+		if canonical, ok := mapCanonicalByAlias[record.AwayTeam]; ok {
+			record.AwayTeam = canonical
+		}
+		recordsCanonicalised = append(recordsCanonicalised, record)
+	}
+	return recordsCanonicalised
+}
+
+/*
+Reads `RawData` records from CSV file (using the mapping of statistic columns given in `PipelineOptions`),
+canonicalises team names using the alias file given in `PipelineOptions`, and applies its scoring rules.
+Exits if the data file can't be read (see `tryLoadRawRecords`).
+*/
+func loadRawRecords(filepath string, options PipelineOptions) []RawData {
+	rawRecords, err := tryLoadRawRecords(filepath, options)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return rawRecords
+}
+
+// Same as `loadRawRecords`, but returns an error instead of exiting if the data file can't be read
+func tryLoadRawRecords(filepath string, options PipelineOptions) ([]RawData, error) {
+	rawRecords, err := parseRawRecordsFromCsv(filepath, readStatColumns(options.StatColumnsFile))
+	if err != nil {
+		return nil, err
+	}
+	return canonicaliseTeamNames(rawRecords, readAliases(options.AliasesFile)), nil
+}
+
+/*
+Folds a name for comparison i.e; lower-cases it, replaces accented letters by their ASCII equivalent
+(eg: "Nürnberg" -> "nurnberg"), drops punctuation and collapses whitespace.
+*/
+func foldName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if folded, ok := mapFoldedRunes[r]; ok {
+			builder.WriteString(folded)
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else if unicode.IsSpace(r) || r == '-' || r == '_' {
+			builder.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// Gets Levenshtein edit distance between two strings (by runes)
+func getEditDistance(a string, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previousRow := make([]int, len(runesB)+1)
+	for j := range previousRow {
+		previousRow[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		currentRow := make([]int, len(runesB)+1)
+		currentRow[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			currentRow[j] = minOfInts(previousRow[j]+1, currentRow[j-1]+1, previousRow[j-1]+cost)
+		}
+		previousRow = currentRow
+	}
+	return previousRow[len(runesB)]
+}
+
+func minOfInts(nums ...int) int {
+	minimum := nums[0]
+	for _, num := range nums {
+		if num < minimum {
+			minimum = num
+		}
+	}
+	return minimum
+}
+
+// Returns true if `short` can be obtained by deleting letters from `long`, keeping the same first letter (eg: "utd", "united")
+func isAbbreviationOf(short string, long string) bool {
+	if short == "" || long == "" || short[0] != long[0] || len(short) > len(long) {
+		return false
+	}
+	idx := 0
+	for i := 0; i < len(long) && idx < len(short); i++ {
+		if long[i] == short[idx] {
+			idx++
+		}
+	}
+	return idx == len(short)
+}
+
+/*
+Returns true if both folded names have the same number of words, and each word of one name abbreviates the corresponding
+word of the other. Eg: "man united" and "manchester utd"
+*/
+func isAbbreviatedName(foldedA string, foldedB string) bool {
+	wordsA, wordsB := strings.Fields(foldedA), strings.Fields(foldedB)
+	if len(wordsA) != len(wordsB) || len(wordsA) == 0 {
+		return false
+	}
+	for idx := range wordsA {
+		if !isAbbreviationOf(wordsA[idx], wordsB[idx]) && !isAbbreviationOf(wordsB[idx], wordsA[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Gets suspiciously similar pairs of team names from `RawData` records. Names are flagged when they are the same after
+folding (case, accents, punctuation, whitespace), when they abbreviate each other word by word, or when their folded
+edit distance is at most `maxEditDistance` (only for names long enough for the distance to be meaningful).
+*/
+func getAliasSuggestions(records []RawData, maxEditDistance int) []AliasSuggestion {
+	teams := getUniqueTeamNames(records)
+	sliceSuggestions := []AliasSuggestion{}
+	for i := 0; i < len(teams); i++ {
+		for j := i + 1; j < len(teams); j++ {
+			foldedA, foldedB := foldName(teams[i]), foldName(teams[j])
+			distance := getEditDistance(foldedA, foldedB)
+			shorterLength := minOfInts(len([]rune(foldedA)), len([]rune(foldedB)))
+			reason := ""
+			if foldedA == foldedB {
+				reason = "Same after normalisation"
+			} else if isAbbreviatedName(foldedA, foldedB) {
+				reason = "Abbreviation"
+			} else if distance <= maxEditDistance && distance*3 < shorterLength {
+				reason = "Small edit distance"
+			}
+			if reason == "" {
+				continue
+			}
+			tempObj := AliasSuggestion{
+				Name:           teams[i],
+				SimilarTo:      teams[j],
+				Reason:         reason,
+				EditDistance:   distance,
+				NameGames:      getGamesPlayedCount(records, teams[i]),
+				SimilarToGames: getGamesPlayedCount(records, teams[j]),
+			}
+			sliceSuggestions = append(sliceSuggestions, tempObj)
+		}
+	}
+	sort.SliceStable(sliceSuggestions, func(i, j int) bool {
+		return sliceSuggestions[i].EditDistance < sliceSuggestions[j].EditDistance
+	})
+	return sliceSuggestions
+}
+
+// Saves slice having objects of `AliasSuggestion` struct to CSV file
+func saveAliasSuggestionsToCsv(sliceData []AliasSuggestion, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&AliasSuggestion{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+/*
+Command that detects suspiciously similar team names across all data files (after applying existing aliases),
+and saves them for curating the alias file.
+*/
+func runAliasesCommand(args []string) {
+	flagSet := flag.NewFlagSet("aliases", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	maxEditDistance := flagSet.Int("max-distance", 2, "Maximum edit distance between names to be flagged as similar")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+	allRecords := []RawData{}
+	for _, filename := range getListOfDataFilenames() {
+		allRecords = append(allRecords, loadRawRecords(pathDataFolder+"/"+filename, *options)...)
+	}
+	sliceSuggestions := getAliasSuggestions(allRecords, *maxEditDistance)
+	for _, obj := range sliceSuggestions {
+		fmt.Println(obj.Name + " <-> " + obj.SimilarTo + " (" + obj.Reason + ")")
+	}
+	saveAliasSuggestionsToCsv(sliceSuggestions, pathResultsFolder+"/Alias Suggestions.csv")
+	fmt.Println("Found " + strconv.Itoa(len(sliceSuggestions)) + " suspiciously similar team-names")
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+)
+
+// Folder having the pipeline cache of every data file (see `PipelineCache`)
+const pathCacheFolder = ".cache"
+
+/*
+Version of the pipeline cache. Bump it whenever aggregate state, or the results derived from it, are computed
+differently, so that caches saved by older versions are stale.
+*/
+const pipelineCacheVersion = 2
+
+// Constants - Statuses of a data file as compared to its pipeline cache
+const (
+	cacheStale     = "stale"     // No usable cache i.e; missing, edited data file, or changed parameters
+	cacheUnchanged = "unchanged" // Data file and parameters are same as when the cache was saved
+	cacheAppended  = "appended"  // Data file only grew by appended matches since the cache was saved
+)
+
+/*
+Struct to store aggregate state of the matches of a data file, which is updated match by match (see `foldRecord`).
+Absolute stats, latest form and Elo ratings are derived from it, without going over all matches again.
+*/
+type AggregateState struct {
+	MapStatsByTeam              map[string]StatsAbs
+	MapFormByTeam               map[string]string // WLD of latest games, latest first
+	MapLatestPointsByTeam       map[string][]int  // Points of latest games, latest first
+	MapFormByIndividual         map[string]string // Same as `MapFormByTeam`, for individuals of 2v2 games
+	MapLatestPointsByIndividual map[string][]int
+	MapEloByTeam                map[string]float64
+}
+
+// Struct to store the pipeline cache of a data file i.e; its aggregate state, along with what it was computed from
+type PipelineCache struct {
+	ContentHash string // Hash of the contents of the data file
+	Size        int    // Size of the data file (in bytes)
+	ParamsHash  string // Hash of the options and scoring rules the state was computed with
+	NumRecords  int    // Number of `RawData` records folded into the state
+	State       AggregateState
+	OutputPaths []string // Paths to the results saved by the run that saved the cache
+}
+
+func newAggregateState() AggregateState {
+	return AggregateState{
+		MapStatsByTeam:              map[string]StatsAbs{},
+		MapFormByTeam:               map[string]string{},
+		MapLatestPointsByTeam:       map[string][]int{},
+		MapFormByIndividual:         map[string]string{},
+		MapLatestPointsByIndividual: map[string][]int{},
+		MapEloByTeam:                map[string]float64{},
+	}
+}
+
+// Gets result of a match i.e; "W", "L" or "D", from goals scored and allowed
+func getResultLetter(goalsFor int, goalsAgainst int) string {
+	if goalsFor > goalsAgainst {
+		return "W"
+	} else if goalsFor < goalsAgainst {
+		return "L"
+	}
+	return "D"
+}
+
+// Prepends the result and points of a match to latest form, keeping only the latest `nLatestGames` games
+func prependToForm(mapForm map[string]string, mapLatestPoints map[string][]int, name string, result string, points int, nLatestGames int) {
+	form := result + mapForm[name]
+	latestPoints := append([]int{points}, mapLatestPoints[name]...)
+	if len(form) > nLatestGames {
+		form = form[:nLatestGames]
+		latestPoints = latestPoints[:nLatestGames]
+	}
+	mapForm[name] = form
+	mapLatestPoints[name] = latestPoints
+}
+
+// Adds a match to absolute stats of a team, from the perspective of the home side (if `isHome` is true) or away side
+func addMatchToStatsAbs(stats StatsAbs, record RawData, isHome bool, rules ScoringRules) StatsAbs {
+	goalsFor, goalsAgainst := getScoreForTable(record, rules)
+	if !isHome {
+		goalsFor, goalsAgainst = goalsAgainst, goalsFor
+	}
+	stats.GamesPlayed++
+	stats.Points += getPointsFromMatch(record, isHome, rules)
+	stats.GoalDifference += goalsFor - goalsAgainst
+	stats.GoalsScored += goalsFor
+	stats.GoalsAllowed += goalsAgainst
+	goalMargin := goalsFor - goalsAgainst
+	if goalMargin > 0 {
+		stats.Wins++
+		if goalMargin >= rules.BigResultGoalMargin {
+			stats.BigWins++
+		}
+		if goalMargin == 1 {
+			stats.WinsBy1++
+		} else if goalMargin == 2 {
+			stats.WinsBy2++
+		} else {
+			stats.WinsBy3Plus++
+		}
+	} else if goalMargin < 0 {
+		stats.Losses++
+		if -goalMargin >= rules.BigResultGoalMargin {
+			stats.BigLosses++
+		}
+		if goalMargin == -1 {
+			stats.LossesBy1++
+		} else if goalMargin == -2 {
+			stats.LossesBy2++
+		} else {
+			stats.LossesBy3Plus++
+		}
+	} else {
+		stats.Draws++
+	}
+	if goalsAgainst == 0 {
+		stats.CleanSheets++
+	}
+	if goalsFor == 0 {
+		stats.CleanSheetsAgainst++
+	}
+	return stats
+}
+
+/*
+Folds a match into the aggregate state i.e; updates absolute stats, latest form (of teams, and of individuals as per
+the 2v2 naming convention) and Elo ratings. Folding all records in order gives the same results as computing them
+from all records at once.
+*/
+func (state *AggregateState) foldRecord(record RawData, nLatestGames int, rules ScoringRules) {
+	homeStats, awayStats := state.MapStatsByTeam[record.HomeTeam], state.MapStatsByTeam[record.AwayTeam]
+	homeStats.Team, awayStats.Team = record.HomeTeam, record.AwayTeam
+	state.MapStatsByTeam[record.HomeTeam] = addMatchToStatsAbs(homeStats, record, true, rules)
+	state.MapStatsByTeam[record.AwayTeam] = addMatchToStatsAbs(awayStats, record, false, rules)
+
+	homeGoals, awayGoals := getScoreForTable(record, rules)
+	homeResult, awayResult := getResultLetter(homeGoals, awayGoals), getResultLetter(awayGoals, homeGoals)
+	homePoints, awayPoints := getPointsFromMatch(record, true, rules), getPointsFromMatch(record, false, rules)
+	prependToForm(state.MapFormByTeam, state.MapLatestPointsByTeam, record.HomeTeam, homeResult, homePoints, nLatestGames)
+	prependToForm(state.MapFormByTeam, state.MapLatestPointsByTeam, record.AwayTeam, awayResult, awayPoints, nLatestGames)
+
+	homeMembers := individualNamePattern.FindAllString(record.HomeTeam, -1)
+	for _, individual := range homeMembers {
+		prependToForm(state.MapFormByIndividual, state.MapLatestPointsByIndividual, individual, homeResult, homePoints, nLatestGames)
+	}
+	for _, individual := range individualNamePattern.FindAllString(record.AwayTeam, -1) {
+		if !stringInSlice(individual, homeMembers) {
+			prependToForm(state.MapFormByIndividual, state.MapLatestPointsByIndividual, individual, awayResult, awayPoints, nLatestGames)
+		}
+	}
+
+	updateEloRatings(state.MapEloByTeam, record, rules)
+}
+
+// Gets absolute stats of teams from the aggregate state, in alphabetical order of teams (as per `getAbsoluteStats`)
+func (state *AggregateState) getAbsoluteStats() []StatsAbs {
+	teams := []string{}
+	for team := range state.MapStatsByTeam {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	sliceAbsoluteStats := []StatsAbs{}
+	for _, team := range teams {
+		sliceAbsoluteStats = append(sliceAbsoluteStats, state.MapStatsByTeam[team])
+	}
+	return sliceAbsoluteStats
+}
+
+/*
+Gets latest form of teams (or individuals, if `solo` is true) from the aggregate state, in alphabetical order
+(as per `getLatestForm` and `getLatestFormSolo`)
+*/
+func (state *AggregateState) getLatestForm(solo bool) []LatestForm {
+	mapForm, mapLatestPoints := state.MapFormByTeam, state.MapLatestPointsByTeam
+	if solo {
+		mapForm, mapLatestPoints = state.MapFormByIndividual, state.MapLatestPointsByIndividual
+	}
+	names := []string{}
+	for name := range mapForm {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sliceLatestFormData := []LatestForm{}
+	for _, name := range names {
+		points := 0
+		for _, pointsFromMatch := range mapLatestPoints[name] {
+			points += pointsFromMatch
+		}
+		numGamesConsidered := len(mapLatestPoints[name])
+		tempObj := LatestForm{
+			Rank:               0,
+			Team:               name,
+			Form:               mapForm[name],
+			LatestPPG:          round(float64(points)/float64(numGamesConsidered), 4),
+			NumGamesConsidered: numGamesConsidered,
+		}
+		sliceLatestFormData = append(sliceLatestFormData, tempObj)
+	}
+	return sliceLatestFormData
+}
+
+/*
+Gets hash of the contents of an optional input file (eg: aliases file). Missing files are hashed as empty, since
+they're treated as empty when read.
+*/
+func getInputFileHash(filepath string) string {
+	if filepath == "" {
+		return ""
+	}
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return ""
+	}
+	return getContentHash(content)
+}
+
+/*
+Gets hash of the parameters that aggregate state depends on i.e; cache version, options (including scoring rules),
+contents of input files and number of latest games considered for form. Options that don't change aggregate state (i.e; the
+database, the cache itself, leaderboards) are left out.
+*/
+func getParamsHash(options PipelineOptions, nLatestGames int) string {
+	options.DatabasePath = ""
+	options.NoCache = false
+	options.Leaderboard, options.LeaderboardsFile = "", ""
+	mapHashByInputFile := map[string]string{
+		"Manifest":        getInputFileHash(options.Manifest),
+		"AliasesFile":     getInputFileHash(options.AliasesFile),
+		"StatColumnsFile": getInputFileHash(options.StatColumnsFile),
+	}
+	paramsJson, _ := json.Marshal(map[string]interface{}{
+		"Version":         pipelineCacheVersion,
+		"PipelineOptions": options,
+		"InputFiles":      mapHashByInputFile,
+		"LatestGames":     nLatestGames,
+	})
+	hash := sha256.Sum256(paramsJson)
+	return hex.EncodeToString(hash[:])
+}
+
+// Checks if all results saved by the run that saved the pipeline cache still exist
+func hasAllOutputs(cache *PipelineCache) bool {
+	if cache == nil || len(cache.OutputPaths) == 0 {
+		return false
+	}
+	for _, path := range cache.OutputPaths {
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Gets hash of the contents of a data file
+func getContentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// Gets path to the pipeline cache of a data file
+func getCachePath(filename string) string {
+	return pathCacheFolder + "/" + removeExtension(filename) + ".json"
+}
+
+// Loads the pipeline cache of a data file. Returns nil if it doesn't exist or can't be read
+func loadPipelineCache(filename string) *PipelineCache {
+	cacheJson, err := os.ReadFile(getCachePath(filename))
+	if err != nil {
+		return nil
+	}
+	cache := &PipelineCache{}
+	if err := json.Unmarshal(cacheJson, cache); err != nil {
+		return nil
+	}
+	return cache
+}
+
+// Saves the pipeline cache of a data file. Failing to save the cache isn't fatal, as it only speeds up later runs
+func savePipelineCache(filename string, cache PipelineCache) error {
+	if err := os.MkdirAll(pathCacheFolder, 0777); err != nil {
+		return err
+	}
+	cacheJson, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getCachePath(filename), cacheJson, 0666)
+}
+
+/*
+Gets status of a data file (having the given contents and `RawData` records) as compared to its pipeline cache.
+A data file counts as appended only if its previous contents (ending with a newline) are a prefix of its contents,
+and it has at least as many records as were folded.
+*/
+func getCacheStatus(cache *PipelineCache, content []byte, records []RawData, paramsHash string) string {
+	if cache == nil || cache.ParamsHash != paramsHash || len(content) < cache.Size || len(records) < cache.NumRecords {
+		return cacheStale
+	}
+	if len(content) == cache.Size {
+		if getContentHash(content) == cache.ContentHash {
+			return cacheUnchanged
+		}
+		return cacheStale
+	}
+	previousContent := content[:cache.Size]
+	if getContentHash(previousContent) != cache.ContentHash || (cache.Size > 0 && !bytes.HasSuffix(previousContent, []byte("\n"))) {
+		return cacheStale
+	}
+	return cacheAppended
+}
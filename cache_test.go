@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Gets `RawData` records of every data file, by filename
+func getTestRecordsByFilename(t *testing.T) map[string][]RawData {
+	entries, err := os.ReadDir(pathDataFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapRecordsByFilename := map[string][]RawData{}
+	for _, entry := range entries {
+		mapRecordsByFilename[entry.Name()] = loadRawRecords(pathDataFolder+"/"+entry.Name(), PipelineOptions{ScoringRules: defaultScoringRules})
+	}
+	return mapRecordsByFilename
+}
+
+// Checks that results derived from aggregate state are the same as the ones computed from all records at once
+func checkStateMatchesFullRecompute(t *testing.T, label string, state AggregateState, records []RawData, nLatestGames int, rules ScoringRules) {
+	if got, want := state.getAbsoluteStats(), getAbsoluteStats(records, rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: absolute stats differ\nfold: %+v\nfull: %+v", label, got, want)
+	}
+	if got, want := state.getLatestForm(false), getLatestForm(records, nLatestGames, rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: latest form differs\nfold: %+v\nfull: %+v", label, got, want)
+	}
+	if isValid2v2Naming(records) {
+		if got, want := state.getLatestForm(true), getLatestFormSolo(records, nLatestGames, rules); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: latest form of individuals differs\nfold: %+v\nfull: %+v", label, got, want)
+		}
+	}
+	if got, want := state.MapEloByTeam, getEloRatings(records, rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: Elo ratings differ\nfold: %v\nfull: %v", label, got, want)
+	}
+}
+
+func TestFoldRecordMatchesFullRecompute(t *testing.T) {
+	nLatestGames := 10
+	bigMarginOfOne := defaultScoringRules
+	bigMarginOfOne.BigResultGoalMargin = 1
+	for _, rules := range []ScoringRules{defaultScoringRules, bigMarginOfOne} {
+		for filename, records := range getTestRecordsByFilename(t) {
+			state := newAggregateState()
+			for _, record := range records {
+				state.foldRecord(record, nLatestGames, rules)
+			}
+			checkStateMatchesFullRecompute(t, filename, state, records, nLatestGames, rules)
+		}
+	}
+}
+
+func TestFoldAppendedRecordsOntoSavedStateMatchesFullRecompute(t *testing.T) {
+	nLatestGames := 10
+	for filename, records := range getTestRecordsByFilename(t) {
+		numCached := len(records) * 2 / 3
+		state := newAggregateState()
+		for _, record := range records[:numCached] {
+			state.foldRecord(record, nLatestGames, defaultScoringRules)
+		}
+		// State goes through JSON, as it does when it's saved to and loaded from the pipeline cache
+		stateJson, err := json.Marshal(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loadedState := AggregateState{}
+		if err := json.Unmarshal(stateJson, &loadedState); err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range records[numCached:] {
+			loadedState.foldRecord(record, nLatestGames, defaultScoringRules)
+		}
+		checkStateMatchesFullRecompute(t, filename, loadedState, records, nLatestGames, defaultScoringRules)
+	}
+}
+
+func TestGetCacheStatus(t *testing.T) {
+	content := []byte("HomeTeam,HomeGoals,AwayGoals,AwayTeam\nA,1,0,B\n")
+	records := []RawData{{HomeTeam: "A", HomeGoals: 1, AwayGoals: 0, AwayTeam: "B"}}
+	cache := &PipelineCache{ContentHash: getContentHash(content), Size: len(content), ParamsHash: "params", NumRecords: 1}
+	appendedRecords := append(records, RawData{HomeTeam: "B", HomeGoals: 2, AwayGoals: 2, AwayTeam: "A"})
+	testCases := []struct {
+		label   string
+		content string
+		records []RawData
+		params  string
+		want    string
+	}{
+		{"unchanged", string(content), records, "params", cacheUnchanged},
+		{"appended", string(content) + "B,2,2,A\n", appendedRecords, "params", cacheAppended},
+		{"edited", "HomeTeam,HomeGoals,AwayGoals,AwayTeam\nA,1,1,B\n", records, "params", cacheStale},
+		{"edited and appended", "HomeTeam,HomeGoals,AwayGoals,AwayTeam\nA,1,1,B\nB,2,2,A\n", appendedRecords, "params", cacheStale},
+		{"changed parameters", string(content), records, "other params", cacheStale},
+	}
+	for _, testCase := range testCases {
+		if got := getCacheStatus(cache, []byte(testCase.content), testCase.records, testCase.params); got != testCase.want {
+			t.Errorf("%s: got %q, want %q", testCase.label, got, testCase.want)
+		}
+	}
+	if got := getCacheStatus(nil, content, records, "params"); got != cacheStale {
+		t.Errorf("missing cache: got %q, want %q", got, cacheStale)
+	}
+}
+
+func TestGetParamsHashChangesWithInputFileContents(t *testing.T) {
+	aliasesFile := t.TempDir() + "/aliases.csv"
+	options := PipelineOptions{AliasesFile: aliasesFile, ScoringRules: defaultScoringRules}
+	if err := os.WriteFile(aliasesFile, []byte("Alias,Canonical\nMan Utd,Manchester United\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	hashBefore := getParamsHash(options, 10)
+	if err := os.WriteFile(aliasesFile, []byte("Alias,Canonical\nMan Utd,Man United\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if hashAfter := getParamsHash(options, 10); hashAfter == hashBefore {
+		t.Error("params hash didn't change when contents of the aliases file changed")
+	}
+}
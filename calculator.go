@@ -2,6 +2,8 @@ package main
 
 import (
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -12,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/structs"
 )
@@ -22,12 +25,36 @@ const (
 	pathResultsFolder = "results"
 )
 
+// Layouts accepted for the optional "Date" column of data files
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "02/01/06", "2006/01/02"}
+
+// Pattern of the unique-names of individuals making up a 2v2 team-name i.e; "AnkurNishant" -> "Ankur", "Nishant"
+var individualNamePattern = regexp.MustCompile(`[A-Z][^A-Z]*`)
+
 // Struct to store raw data
 type RawData struct {
 	HomeTeam  string
 	HomeGoals int
 	AwayGoals int
 	AwayTeam  string
+	Date      time.Time // Zero if the data file has no "Date" column
+	// Half-time goals, if the data file has "HTHG" and "HTAG" columns
+	HasHalfTime       bool
+	HalfTimeHomeGoals int
+	HalfTimeAwayGoals int
+	// Score after extra time (including regulation goals), if the match went to extra time
+	HasExtraTime bool
+	HomeGoalsAET int
+	AwayGoalsAET int
+	// Penalty shootout score, if the match was decided by a shootout
+	HasShootout   bool
+	HomePenalties int
+	AwayPenalties int
+	Stage         string // Stage/round of a competition i.e; "Group A", "Quarter-final". Empty if not available
+	LineNumber    int    // Line of the data file having the match (1 being the header). Zero if not read from a data file
+	// Per-side match statistics (i.e; shots, xG, cards) keyed by statistic name. Nil if not available
+	HomeStats map[string]float64
+	AwayStats map[string]float64
 }
 
 // Struct to store absolute tabular statistics
@@ -44,26 +71,39 @@ type StatsAbs struct {
 	GoalsAllowed       int
 	CleanSheets        int
 	CleanSheetsAgainst int
-	BigWins            int
+	BigWins            int // Wins by at least `ScoringRules.BigResultGoalMargin` goals
 	BigLosses          int
+	WinsBy1            int
+	WinsBy2            int
+	WinsBy3Plus        int
+	LossesBy1          int
+	LossesBy2          int
+	LossesBy3Plus      int
 }
 
 // Struct to store normalized tabular statistics i.e; StatAbs / GamesPlayed
 type StatsNorm struct {
-	Rank        int
-	Team        string
-	GamesPlayed int
-	PPG         float64
-	GDPG        float64
-	WinPct      float64
-	LossPct     float64
-	DrawPct     float64
-	GSPG        float64
-	GAPG        float64
-	CsPct       float64
-	CsaPct      float64
-	BigWinPct   float64
-	BigLossPct  float64
+	Rank           int
+	Team           string
+	GamesPlayed    int
+	PPG            float64
+	ShrunkPPG      float64 // PPG pulled towards the league mean (see `PipelineOptions.PriorStrength`)
+	GDPG           float64
+	WinPct         float64
+	LossPct        float64
+	DrawPct        float64
+	GSPG           float64
+	GAPG           float64
+	CsPct          float64
+	CsaPct         float64
+	BigWinPct      float64
+	BigLossPct     float64
+	WinBy1Pct      float64
+	WinBy2Pct      float64
+	WinBy3PlusPct  float64
+	LossBy1Pct     float64
+	LossBy2Pct     float64
+	LossBy3PlusPct float64
 }
 
 // Struct to store latest form (decided by latest PPG)
@@ -75,6 +115,58 @@ type LatestForm struct {
 	NumGamesConsidered int
 }
 
+// Constants - Ways of counting matches decided by a penalty shootout
+const (
+	shootoutModeDraw   = "draw"   // Shootouts count as draws
+	shootoutModePoints = "points" // Shootout winner and loser get special points
+)
+
+// Struct to store rules deciding how extra time and penalty shootouts count in tables
+type ScoringRules struct {
+	CountExtraTime        bool   // Use the score after extra time (instead of regulation time) in tables
+	ShootoutMode          string // One of `shootoutModeDraw`, `shootoutModePoints`
+	PointsForShootoutWin  int    // Used if `ShootoutMode` is `shootoutModePoints`
+	PointsForShootoutLoss int    // Used if `ShootoutMode` is `shootoutModePoints`
+	BigResultGoalMargin   int    // Goal margin at or above which a win/loss counts as a big win/loss
+}
+
+// Default scoring rules (see the flags registered by `registerPipelineFlags`)
+var defaultScoringRules = ScoringRules{
+	CountExtraTime:        true,
+	ShootoutMode:          shootoutModeDraw,
+	PointsForShootoutWin:  2,
+	PointsForShootoutLoss: 1,
+	BigResultGoalMargin:   3,
+}
+
+// Struct to store options that tweak how results are computed
+type PipelineOptions struct {
+	MinGamesPlayed      int          // Teams/individuals with fewer games are left out of the normalized rankings
+	PriorStrength       float64      // Number of league-average games blended into each PPG when computing `ShrunkPPG`
+	RankByShrunkPPG     bool         // Rank normalized stats by `ShrunkPPG` instead of `PPG`
+	Intervals           bool         // Compute confidence intervals for normalized stats
+	ConfidenceLevel     float64      // Confidence level of the intervals (eg: 0.95)
+	BootstrapSamples    int          // Number of bootstrap resamples used for intervals of per-game rates
+	PythagoreanExponent float64      // Exponent used for Pythagorean expectation. Fitted to the data if not positive
+	Manifest            string       // Path to CSV file mapping data files to competitions/seasons (optional)
+	AliasesFile         string       // Path to CSV file mapping variants of team names to canonical names (optional)
+	MaxGoals            int          // Goals by one side above which a score is flagged as absurd
+	FailOnWarnings      bool         // Don't compute stats of data files having validation warnings
+	ValidationFormat    string       // Format of validation reports i.e; "csv" or "json"
+	StatColumnsFile     string       // Path to CSV file mapping columns to per-side match statistics (optional)
+	QualifiersPerGroup  int          // Number of teams qualifying from each group of a tournament
+	BestNextPlaced      int          // Number of best teams placed just below the qualifiers of each group that also qualify (eg: best thirds)
+	Bands               string       // Bands of finishing positions for clinch statuses i.e; "Title=1,Top 4=1-4"
+	NumRelegated        int          // Number of positions at the bottom of the table that are relegated (0 if none)
+	MaxSearchNodes      int          // Maximum number of outcomes searched per team while computing clinch statuses
+	DatabasePath        string       // Path to SQLite database storing matches and computed tables (optional)
+	NoCache             bool         // Recompute every data file from scratch, ignoring the pipeline cache
+	Filter              string       // Filter expression selecting the matches that stats are computed from (see `parseMatchFilters`)
+	Leaderboard         string       // Query of a custom leaderboard (see `parseLeaderboardQuery`)
+	LeaderboardsFile    string       // Path to CSV file having columns Name, Query of saved custom leaderboards (optional)
+	ScoringRules        ScoringRules // How extra time, penalty shootouts and big results count in tables
+}
+
 /*
 Method that gets slice of stringified elements of `StatsAbs` struct (by record).
 NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
@@ -96,6 +188,12 @@ func (obj StatsAbs) ListStringifiedValues() []string {
 	values = append(values, strconv.Itoa(obj.CleanSheetsAgainst))
 	values = append(values, strconv.Itoa(obj.BigWins))
 	values = append(values, strconv.Itoa(obj.BigLosses))
+	values = append(values, strconv.Itoa(obj.WinsBy1))
+	values = append(values, strconv.Itoa(obj.WinsBy2))
+	values = append(values, strconv.Itoa(obj.WinsBy3Plus))
+	values = append(values, strconv.Itoa(obj.LossesBy1))
+	values = append(values, strconv.Itoa(obj.LossesBy2))
+	values = append(values, strconv.Itoa(obj.LossesBy3Plus))
 	return values
 }
 
@@ -110,6 +208,7 @@ func (obj StatsNorm) ListStringifiedValues() []string {
 	values = append(values, obj.Team)
 	values = append(values, strconv.Itoa(obj.GamesPlayed))
 	values = append(values, fmt.Sprintf("%g", obj.PPG))
+	values = append(values, fmt.Sprintf("%g", obj.ShrunkPPG))
 	values = append(values, fmt.Sprintf("%g", obj.GDPG))
 	values = append(values, fmt.Sprintf("%g", obj.WinPct))
 	values = append(values, fmt.Sprintf("%g", obj.LossPct))
@@ -120,6 +219,12 @@ func (obj StatsNorm) ListStringifiedValues() []string {
 	values = append(values, fmt.Sprintf("%g", obj.CsaPct))
 	values = append(values, fmt.Sprintf("%g", obj.BigWinPct))
 	values = append(values, fmt.Sprintf("%g", obj.BigLossPct))
+	values = append(values, fmt.Sprintf("%g", obj.WinBy1Pct))
+	values = append(values, fmt.Sprintf("%g", obj.WinBy2Pct))
+	values = append(values, fmt.Sprintf("%g", obj.WinBy3PlusPct))
+	values = append(values, fmt.Sprintf("%g", obj.LossBy1Pct))
+	values = append(values, fmt.Sprintf("%g", obj.LossBy2Pct))
+	values = append(values, fmt.Sprintf("%g", obj.LossBy3PlusPct))
 	return values
 }
 
@@ -138,15 +243,81 @@ func (obj LatestForm) ListStringifiedValues() []string {
 	return values
 }
 
-// Read CSV file having columns "HomeTeam, HomeGoals, AwayGoals, AwayTeam" in that order
+/*
+Gets map of lower-cased header name -> column index, for the optional columns of a data file.
+The first 4 columns are positional, so they aren't included.
+*/
+func getOptionalColumnIndexes(header []string) map[string]int {
+	mapColumnIndexByName := map[string]int{}
+	for idx := 4; idx < len(header); idx++ {
+		mapColumnIndexByName[strings.ToLower(strings.TrimSpace(header[idx]))] = idx
+	}
+	return mapColumnIndexByName
+}
+
+// Parses date using any of the accepted `dateLayouts`
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var date time.Time
+		date, err = time.Parse(layout, strings.TrimSpace(value))
+		if err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+/*
+Reads an optional pair of home/away goal columns (i.e; half-time goals) from a row of a data file.
+Returns false if the data file doesn't have the columns, or if the cells are empty.
+*/
+func readOptionalScore(row []string, mapColumnIndexByName map[string]int, homeColumn string, awayColumn string, lineCount int) (bool, int, int, error) {
+	idxHome, okHome := mapColumnIndexByName[homeColumn]
+	idxAway, okAway := mapColumnIndexByName[awayColumn]
+	if !okHome || !okAway || row[idxHome] == "" || row[idxAway] == "" {
+		return false, 0, 0, nil
+	}
+	homeGoals, strConvErrHome := strconv.Atoi(row[idxHome])
+	awayGoals, strConvErrAway := strconv.Atoi(row[idxAway])
+	if strConvErrHome != nil || strConvErrAway != nil {
+		return false, 0, 0, errors.New("Error while converting " + strings.ToUpper(homeColumn) + "/" + strings.ToUpper(awayColumn) + " to int at line " + strconv.Itoa(lineCount))
+	}
+	return true, homeGoals, awayGoals, nil
+}
+
+// Read CSV file having columns "HomeTeam, HomeGoals, AwayGoals, AwayTeam" in that order (see `readRawRecordsFromCsvWithStatColumns`)
 func readRawRecordsFromCsv(filepath string) []RawData {
+	return readRawRecordsFromCsvWithStatColumns(filepath, defaultStatColumns)
+}
+
+// Same as `parseRawRecordsFromCsv`, but exits if the data file can't be read
+func readRawRecordsFromCsvWithStatColumns(filepath string, mapStatColumnByName map[string]StatColumn) []RawData {
+	records, err := parseRawRecordsFromCsv(filepath, mapStatColumnByName)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return records
+}
+
+/*
+Read CSV file having columns "HomeTeam, HomeGoals, AwayGoals, AwayTeam" in that order.
+Rows having empty goals are fixtures yet to be played, and are skipped.
+Optional columns are recognised by their header name, and can be in any position after those i.e;
+"Date", "HTHG"/"HTAG" (half-time goals), "AETHG"/"AETAG" (goals after extra time), "PSHG"/"PSAG" (penalty shootout goals),
+"Stage" (or "Round"), and per-side statistics mapped by `mapStatColumnByName`.
+Returns an error if the data file can't be opened, or if any of its rows can't be parsed.
+*/
+func parseRawRecordsFromCsv(filepath string, mapStatColumnByName map[string]StatColumn) ([]RawData, error) {
 	csvfile, err := os.Open(filepath)
 	if err != nil {
-		log.Fatalln("Couldn't open the CSV file", err)
+		return nil, errors.New("Couldn't open the CSV file " + err.Error())
 	}
+	defer csvfile.Close()
 	r := csv.NewReader(csvfile)
 	lineCount := 0
 	records := []RawData{}
+	mapColumnIndexByName := map[string]int{}
 	for {
 		lineCount++
 		record, err := r.Read()
@@ -154,27 +325,60 @@ func readRawRecordsFromCsv(filepath string) []RawData {
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
-		if lineCount != 1 {
+		if lineCount == 1 {
+			if len(record) < 4 {
+				return nil, errors.New("'" + filepath + "' must have the columns HomeTeam, HomeGoals, AwayGoals, AwayTeam")
+			}
+			mapColumnIndexByName = getOptionalColumnIndexes(record)
+		} else if record[1] == "" && record[2] == "" {
+			continue // Fixture yet to be played
+		} else {
 			homeGoals, strConvErrHome := strconv.Atoi(record[1])
 			awayGoals, strConvErrAway := strconv.Atoi(record[2])
 			if strConvErrHome != nil {
-				log.Fatalln("Error while converting HomeGoals to int", strConvErrHome)
+				return nil, errors.New("Error while converting HomeGoals to int at line " + strconv.Itoa(lineCount) + " " + strConvErrHome.Error())
 			}
 			if strConvErrAway != nil {
-				log.Fatalln("Error while converting AwayGoals to int", strConvErrAway)
+				return nil, errors.New("Error while converting AwayGoals to int at line " + strconv.Itoa(lineCount) + " " + strConvErrAway.Error())
+			}
+			rawRecord := RawData{
+				HomeTeam:   record[0],
+				HomeGoals:  homeGoals,
+				AwayGoals:  awayGoals,
+				AwayTeam:   record[3],
+				LineNumber: lineCount,
+			}
+			if idx, ok := mapColumnIndexByName["date"]; ok && record[idx] != "" {
+				date, dateErr := parseDate(record[idx])
+				if dateErr != nil {
+					return nil, errors.New("Error while parsing Date at line " + strconv.Itoa(lineCount) + " " + dateErr.Error())
+				}
+				rawRecord.Date = date
+			}
+			var scoreErr error
+			if rawRecord.HasHalfTime, rawRecord.HalfTimeHomeGoals, rawRecord.HalfTimeAwayGoals, scoreErr = readOptionalScore(record, mapColumnIndexByName, "hthg", "htag", lineCount); scoreErr != nil {
+				return nil, scoreErr
 			}
-			record := RawData{
-				HomeTeam:  record[0],
-				HomeGoals: homeGoals,
-				AwayGoals: awayGoals,
-				AwayTeam:  record[3],
+			if rawRecord.HasExtraTime, rawRecord.HomeGoalsAET, rawRecord.AwayGoalsAET, scoreErr = readOptionalScore(record, mapColumnIndexByName, "aethg", "aetag", lineCount); scoreErr != nil {
+				return nil, scoreErr
 			}
-			records = append(records, record)
+			if rawRecord.HasShootout, rawRecord.HomePenalties, rawRecord.AwayPenalties, scoreErr = readOptionalScore(record, mapColumnIndexByName, "pshg", "psag", lineCount); scoreErr != nil {
+				return nil, scoreErr
+			}
+			if idx, ok := mapColumnIndexByName["stage"]; ok {
+				rawRecord.Stage = strings.TrimSpace(record[idx])
+			} else if idx, ok := mapColumnIndexByName["round"]; ok {
+				rawRecord.Stage = strings.TrimSpace(record[idx])
+			}
+			if err := fillMatchStatistics(&rawRecord, record, mapColumnIndexByName, mapStatColumnByName, lineCount); err != nil {
+				return nil, err
+			}
+			records = append(records, rawRecord)
 		}
 	}
-	return records
+	return records, nil
 }
 
 func removeExtension(filenameWithExt string) string {
@@ -270,16 +474,6 @@ func isHomeSameAsAway(records []RawData) bool {
 	return false
 }
 
-func printSameHomeAndAwayNames(records []RawData) {
-	for idx, record := range records {
-		homeTeam, awayTeam := record.HomeTeam, record.AwayTeam
-		if homeTeam == awayTeam {
-			idxStringified := strconv.Itoa(idx + 1)
-			fmt.Println("Error - HomeTeam is same as AwayTeam at record number " + idxStringified + ". Team-names given: " + homeTeam + ", " + awayTeam)
-		}
-	}
-}
-
 /*
 NOTE: Used for 2v2 games only.
 Returns true if records have correct 2v2 naming convention; false otherwise
@@ -297,19 +491,6 @@ func isValid2v2Naming(records []RawData) bool {
 	return true
 }
 
-func printInvalid2v2TeamNames(records []RawData) {
-	re := regexp.MustCompile(`[A-Z][^A-Z]*`)
-	for idx, record := range records {
-		homeTeam, awayTeam := record.HomeTeam, record.AwayTeam
-		homeTeamMembers := re.FindAllString(homeTeam, -1)
-		awayTeamMembers := re.FindAllString(awayTeam, -1)
-		if len(homeTeamMembers) != 2 || len(awayTeamMembers) != 2 {
-			idxStringified := strconv.Itoa(idx + 1)
-			fmt.Println("Error - Incorrect 2v2 naming convention at record number " + idxStringified + ". Team-names given: " + homeTeam + ", " + awayTeam)
-		}
-	}
-}
-
 func getGamesPlayedCount(records []RawData, team string) int {
 	count := 0
 	for _, record := range records {
@@ -322,95 +503,102 @@ func getGamesPlayedCount(records []RawData, team string) int {
 	return count
 }
 
-func getWinCount(records []RawData, team string) int {
+func getWinCount(records []RawData, team string, rules ScoringRules) int {
 	count := 0
 	for _, record := range records {
-		if record.HomeTeam == team && record.HomeGoals > record.AwayGoals {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if record.HomeTeam == team && homeGoals > awayGoals {
 			count++
-		} else if record.AwayTeam == team && record.AwayGoals > record.HomeGoals {
+		} else if record.AwayTeam == team && awayGoals > homeGoals {
 			count++
 		}
 	}
 	return count
 }
 
-func getLossCount(records []RawData, team string) int {
+func getLossCount(records []RawData, team string, rules ScoringRules) int {
 	count := 0
 	for _, record := range records {
-		if record.HomeTeam == team && record.HomeGoals < record.AwayGoals {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if record.HomeTeam == team && homeGoals < awayGoals {
 			count++
-		} else if record.AwayTeam == team && record.AwayGoals < record.HomeGoals {
+		} else if record.AwayTeam == team && awayGoals < homeGoals {
 			count++
 		}
 	}
 	return count
 }
 
-func getDrawCount(records []RawData, team string) int {
+func getDrawCount(records []RawData, team string, rules ScoringRules) int {
 	count := 0
 	for _, record := range records {
-		if record.HomeTeam == team && record.HomeGoals == record.AwayGoals {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if record.HomeTeam == team && homeGoals == awayGoals {
 			count++
-		} else if record.AwayTeam == team && record.AwayGoals == record.HomeGoals {
+		} else if record.AwayTeam == team && awayGoals == homeGoals {
 			count++
 		}
 	}
 	return count
 }
 
-func getGoalsScored(records []RawData, team string) int {
+func getGoalsScored(records []RawData, team string, rules ScoringRules) int {
 	goalsScored := 0
 	for _, record := range records {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
 		if record.HomeTeam == team {
-			goalsScored += record.HomeGoals
+			goalsScored += homeGoals
 		} else if record.AwayTeam == team {
-			goalsScored += record.AwayGoals
+			goalsScored += awayGoals
 		}
 	}
 	return goalsScored
 }
 
-func getGoalsAllowed(records []RawData, team string) int {
+func getGoalsAllowed(records []RawData, team string, rules ScoringRules) int {
 	goalsAllowed := 0
 	for _, record := range records {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
 		if record.HomeTeam == team {
-			goalsAllowed += record.AwayGoals
+			goalsAllowed += awayGoals
 		} else if record.AwayTeam == team {
-			goalsAllowed += record.HomeGoals
+			goalsAllowed += homeGoals
 		}
 	}
 	return goalsAllowed
 }
 
-func getCleanSheets(records []RawData, team string) int {
+func getCleanSheets(records []RawData, team string, rules ScoringRules) int {
 	cleanSheetCount := 0
 	for _, record := range records {
-		if record.HomeTeam == team && record.AwayGoals == 0 {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if record.HomeTeam == team && awayGoals == 0 {
 			cleanSheetCount++
-		} else if record.AwayTeam == team && record.HomeGoals == 0 {
+		} else if record.AwayTeam == team && homeGoals == 0 {
 			cleanSheetCount++
 		}
 	}
 	return cleanSheetCount
 }
 
-func getCleanSheetsAgainst(records []RawData, team string) int {
+func getCleanSheetsAgainst(records []RawData, team string, rules ScoringRules) int {
 	cleanSheetAgainstCount := 0
 	for _, record := range records {
-		if record.HomeTeam == team && record.HomeGoals == 0 {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if record.HomeTeam == team && homeGoals == 0 {
 			cleanSheetAgainstCount++
-		} else if record.AwayTeam == team && record.AwayGoals == 0 {
+		} else if record.AwayTeam == team && awayGoals == 0 {
 			cleanSheetAgainstCount++
 		}
 	}
 	return cleanSheetAgainstCount
 }
 
-func getBigWinCount(records []RawData, team string, margin int) int {
+func getBigWinCount(records []RawData, team string, rules ScoringRules) int {
+	margin := rules.BigResultGoalMargin
 	bigWinCount := 0
 	for _, record := range records {
-		hg := record.HomeGoals
-		ag := record.AwayGoals
+		hg, ag := getScoreForTable(record, rules)
 		goalMargin := int(math.Abs(float64(hg - ag)))
 		if record.HomeTeam == team && hg > ag && goalMargin >= margin {
 			bigWinCount++
@@ -421,11 +609,11 @@ func getBigWinCount(records []RawData, team string, margin int) int {
 	return bigWinCount
 }
 
-func getBigLossCount(records []RawData, team string, margin int) int {
+func getBigLossCount(records []RawData, team string, rules ScoringRules) int {
+	margin := rules.BigResultGoalMargin
 	bigLossCount := 0
 	for _, record := range records {
-		hg := record.HomeGoals
-		ag := record.AwayGoals
+		hg, ag := getScoreForTable(record, rules)
 		goalMargin := int(math.Abs(float64(hg - ag)))
 		if record.HomeTeam == team && hg < ag && goalMargin >= margin {
 			bigLossCount++
@@ -436,35 +624,109 @@ func getBigLossCount(records []RawData, team string, margin int) int {
 	return bigLossCount
 }
 
+// Gets number of wins of a team by a goal margin between `minMargin` and `maxMargin` (inclusive)
+func getWinCountByMargin(records []RawData, team string, minMargin int, maxMargin int, rules ScoringRules) int {
+	winCount := 0
+	for _, record := range records {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		goalMargin := homeGoals - awayGoals
+		if record.AwayTeam == team {
+			goalMargin = -goalMargin
+		}
+		if (record.HomeTeam == team || record.AwayTeam == team) && goalMargin >= minMargin && goalMargin <= maxMargin {
+			winCount++
+		}
+	}
+	return winCount
+}
+
+// Gets number of losses of a team by a goal margin between `minMargin` and `maxMargin` (inclusive)
+func getLossCountByMargin(records []RawData, team string, minMargin int, maxMargin int, rules ScoringRules) int {
+	lossCount := 0
+	for _, record := range records {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		goalMargin := awayGoals - homeGoals
+		if record.AwayTeam == team {
+			goalMargin = -goalMargin
+		}
+		if (record.HomeTeam == team || record.AwayTeam == team) && goalMargin >= minMargin && goalMargin <= maxMargin {
+			lossCount++
+		}
+	}
+	return lossCount
+}
+
+/*
+Gets points won by the home side (if `isHome` is true) or the away side of a match, as per the scoring rules.
+Wins get 3 points, draws 1 and losses 0. Draws decided by a penalty shootout may get special points instead.
+*/
+func getPointsFromMatch(record RawData, isHome bool, rules ScoringRules) int {
+	goalsFor, goalsAgainst := getScoreForTable(record, rules)
+	penaltiesFor, penaltiesAgainst := record.HomePenalties, record.AwayPenalties
+	if !isHome {
+		goalsFor, goalsAgainst = goalsAgainst, goalsFor
+		penaltiesFor, penaltiesAgainst = record.AwayPenalties, record.HomePenalties
+	}
+	if goalsFor > goalsAgainst {
+		return 3
+	} else if goalsFor < goalsAgainst {
+		return 0
+	}
+	if record.HasShootout && rules.ShootoutMode == shootoutModePoints {
+		if penaltiesFor > penaltiesAgainst {
+			return rules.PointsForShootoutWin
+		}
+		return rules.PointsForShootoutLoss
+	}
+	return 1
+}
+
+func getPoints(records []RawData, team string, rules ScoringRules) int {
+	points := 0
+	for _, record := range records {
+		if record.HomeTeam == team {
+			points += getPointsFromMatch(record, true, rules)
+		} else if record.AwayTeam == team {
+			points += getPointsFromMatch(record, false, rules)
+		}
+	}
+	return points
+}
+
 /*
 Gets slice of absolute stats from raw records.
 Returns slice wherein each element of the slice is an object of the struct `StatsAbs`
 */
-func getAbsoluteStats(records []RawData) []StatsAbs {
+func getAbsoluteStats(records []RawData, rules ScoringRules) []StatsAbs {
 	teams := getUniqueTeamNames(records)
-	bigResultGoalMargin := 3 // Will be considered as big result if GoalDifference >= this number
 	sliceAbsoluteStats := []StatsAbs{}
 	for _, team := range teams {
-		wins := getWinCount(records, team)
-		draws := getDrawCount(records, team)
-		gs := getGoalsScored(records, team)
-		ga := getGoalsAllowed(records, team)
+		wins := getWinCount(records, team, rules)
+		draws := getDrawCount(records, team, rules)
+		gs := getGoalsScored(records, team, rules)
+		ga := getGoalsAllowed(records, team, rules)
 		gd := gs - ga
-		points := 3 * wins + draws
+		points := getPoints(records, team, rules)
 		tempAbsoluteStats := StatsAbs{
 			Team:               team,
 			GamesPlayed:        getGamesPlayedCount(records, team),
 			Points:             points,
 			GoalDifference:     gd,
 			Wins:               wins,
-			Losses:             getLossCount(records, team),
+			Losses:             getLossCount(records, team, rules),
 			Draws:              draws,
 			GoalsScored:        gs,
 			GoalsAllowed:       ga,
-			CleanSheets:        getCleanSheets(records, team),
-			CleanSheetsAgainst: getCleanSheetsAgainst(records, team),
-			BigWins:            getBigWinCount(records, team, bigResultGoalMargin),
-			BigLosses:          getBigLossCount(records, team, bigResultGoalMargin),
+			CleanSheets:        getCleanSheets(records, team, rules),
+			CleanSheetsAgainst: getCleanSheetsAgainst(records, team, rules),
+			BigWins:            getBigWinCount(records, team, rules),
+			BigLosses:          getBigLossCount(records, team, rules),
+			WinsBy1:            getWinCountByMargin(records, team, 1, 1, rules),
+			WinsBy2:            getWinCountByMargin(records, team, 2, 2, rules),
+			WinsBy3Plus:        getWinCountByMargin(records, team, 3, math.MaxInt, rules),
+			LossesBy1:          getLossCountByMargin(records, team, 1, 1, rules),
+			LossesBy2:          getLossCountByMargin(records, team, 2, 2, rules),
+			LossesBy3Plus:      getLossCountByMargin(records, team, 3, math.MaxInt, rules),
 		}
 		sliceAbsoluteStats = append(sliceAbsoluteStats, tempAbsoluteStats)
 	}
@@ -481,32 +743,83 @@ func getNormalizedStats(sliceAbsStats []StatsAbs) []StatsNorm {
 	for _, obj := range sliceAbsStats {
 		gamesPlayed := float64(obj.GamesPlayed)
 		tempNormalizedStats := StatsNorm{
-			Team:        obj.Team,
-			GamesPlayed: obj.GamesPlayed,
-			PPG:         round(float64(obj.Points)/gamesPlayed, 4),
-			GDPG:        round(float64(obj.GoalDifference)/gamesPlayed, 3),
-			WinPct:      round(float64(obj.Wins)*hundred/gamesPlayed, 2),
-			LossPct:     round(float64(obj.Losses)*hundred/gamesPlayed, 2),
-			DrawPct:     round(float64(obj.Draws)*hundred/gamesPlayed, 2),
-			GSPG:        round(float64(obj.GoalsScored)/gamesPlayed, 3),
-			GAPG:        round(float64(obj.GoalsAllowed)/gamesPlayed, 3),
-			CsPct:       round(float64(obj.CleanSheets)*hundred/gamesPlayed, 2),
-			CsaPct:      round(float64(obj.CleanSheetsAgainst)*hundred/gamesPlayed, 2),
-			BigWinPct:   round(float64(obj.BigWins)*hundred/gamesPlayed, 2),
-			BigLossPct:  round(float64(obj.BigLosses)*hundred/gamesPlayed, 2),
+			Team:           obj.Team,
+			GamesPlayed:    obj.GamesPlayed,
+			PPG:            round(float64(obj.Points)/gamesPlayed, 4),
+			ShrunkPPG:      round(float64(obj.Points)/gamesPlayed, 4),
+			GDPG:           round(float64(obj.GoalDifference)/gamesPlayed, 3),
+			WinPct:         round(float64(obj.Wins)*hundred/gamesPlayed, 2),
+			LossPct:        round(float64(obj.Losses)*hundred/gamesPlayed, 2),
+			DrawPct:        round(float64(obj.Draws)*hundred/gamesPlayed, 2),
+			GSPG:           round(float64(obj.GoalsScored)/gamesPlayed, 3),
+			GAPG:           round(float64(obj.GoalsAllowed)/gamesPlayed, 3),
+			CsPct:          round(float64(obj.CleanSheets)*hundred/gamesPlayed, 2),
+			CsaPct:         round(float64(obj.CleanSheetsAgainst)*hundred/gamesPlayed, 2),
+			BigWinPct:      round(float64(obj.BigWins)*hundred/gamesPlayed, 2),
+			BigLossPct:     round(float64(obj.BigLosses)*hundred/gamesPlayed, 2),
+			WinBy1Pct:      round(float64(obj.WinsBy1)*hundred/gamesPlayed, 2),
+			WinBy2Pct:      round(float64(obj.WinsBy2)*hundred/gamesPlayed, 2),
+			WinBy3PlusPct:  round(float64(obj.WinsBy3Plus)*hundred/gamesPlayed, 2),
+			LossBy1Pct:     round(float64(obj.LossesBy1)*hundred/gamesPlayed, 2),
+			LossBy2Pct:     round(float64(obj.LossesBy2)*hundred/gamesPlayed, 2),
+			LossBy3PlusPct: round(float64(obj.LossesBy3Plus)*hundred/gamesPlayed, 2),
 		}
 		sliceNormalizedStats = append(sliceNormalizedStats, tempNormalizedStats)
 	}
 	return sliceNormalizedStats
 }
 
+// Gets league-wide PPG i.e; total points over total games played, from slice of absolute stats
+func getLeaguePpg(sliceAbsStats []StatsAbs) float64 {
+	totalPoints, totalGamesPlayed := 0, 0
+	for _, obj := range sliceAbsStats {
+		totalPoints += obj.Points
+		totalGamesPlayed += obj.GamesPlayed
+	}
+	if totalGamesPlayed == 0 {
+		return 0
+	}
+	return float64(totalPoints) / float64(totalGamesPlayed)
+}
+
+/*
+Sets `ShrunkPPG` of each `StatsNorm` object by blending `priorStrength` games played at the league-average PPG
+into the team's actual record i.e; (Points + priorStrength * LeaguePPG) / (GamesPlayed + priorStrength).
+Small samples are pulled strongly towards the league mean, while large samples barely move.
+A `priorStrength` of 0 leaves `ShrunkPPG` equal to `PPG`.
+*/
+func shrinkPpgTowardsLeagueMean(sliceNormalizedStats []StatsNorm, sliceAbsStats []StatsAbs, priorStrength float64) []StatsNorm {
+	leaguePpg := getLeaguePpg(sliceAbsStats)
+	mapPointsByTeam := map[string]int{}
+	for _, obj := range sliceAbsStats {
+		mapPointsByTeam[obj.Team] = obj.Points
+	}
+	sliceNormalizedStatsShrunk := []StatsNorm{}
+	for _, tempStats := range sliceNormalizedStats {
+		points := float64(mapPointsByTeam[tempStats.Team])
+		gamesPlayed := float64(tempStats.GamesPlayed)
+		tempStats.ShrunkPPG = round((points + priorStrength * leaguePpg) / (gamesPlayed + priorStrength), 4)
+		sliceNormalizedStatsShrunk = append(sliceNormalizedStatsShrunk, tempStats)
+	}
+	return sliceNormalizedStatsShrunk
+}
+
+// Keeps only those `StatsNorm` objects having played at least `minGamesPlayed` games
+func filterNormStatsByGamesPlayed(sliceNormalizedStats []StatsNorm, minGamesPlayed int) []StatsNorm {
+	sliceNormalizedStatsQualified := []StatsNorm{}
+	for _, tempStats := range sliceNormalizedStats {
+		if tempStats.GamesPlayed >= minGamesPlayed {
+			sliceNormalizedStatsQualified = append(sliceNormalizedStatsQualified, tempStats)
+		}
+	}
+	return sliceNormalizedStatsQualified
+}
+
 // Sorts absolute stats based on certain metric/s
 func sortAbsStatsByMetric(sliceAbsoluteStats []StatsAbs) []StatsAbs {
 	sort.SliceStable(sliceAbsoluteStats, func(i, j int) bool {
-		pointsOfI := 3 * sliceAbsoluteStats[i].Wins + sliceAbsoluteStats[i].Draws
-		pointsOfJ := 3 * sliceAbsoluteStats[j].Wins + sliceAbsoluteStats[j].Draws
-		ppgOfI := float64(pointsOfI) / float64(sliceAbsoluteStats[i].GamesPlayed)
-		ppgOfJ := float64(pointsOfJ) / float64(sliceAbsoluteStats[j].GamesPlayed)
+		ppgOfI := float64(sliceAbsoluteStats[i].Points) / float64(sliceAbsoluteStats[i].GamesPlayed)
+		ppgOfJ := float64(sliceAbsoluteStats[j].Points) / float64(sliceAbsoluteStats[j].GamesPlayed)
 		return ppgOfI > ppgOfJ
 	})
 	return sliceAbsoluteStats
@@ -520,6 +833,14 @@ func sortNormStatsByMetric(sliceNormalizedStats []StatsNorm) []StatsNorm {
 	return sliceNormalizedStats
 }
 
+// Sorts normalized stats based on `ShrunkPPG`
+func sortNormStatsByShrunkPpg(sliceNormalizedStats []StatsNorm) []StatsNorm {
+	sort.SliceStable(sliceNormalizedStats, func(i, j int) bool {
+		return sliceNormalizedStats[i].ShrunkPPG > sliceNormalizedStats[j].ShrunkPPG
+	})
+	return sliceNormalizedStats
+}
+
 // Sorts latest form based on certain metric/s
 func sortLatestFormByMetric(sliceLatestForm []LatestForm) []LatestForm {
 	sort.SliceStable(sliceLatestForm, func(i, j int) bool {
@@ -580,6 +901,12 @@ func extractIndividualStatsFromTeamStats(individual string, sliceTeamAbsStats []
 			mapStatsByIndividual["CleanSheetsAgainst"] += objStat.CleanSheetsAgainst
 			mapStatsByIndividual["BigWins"] += objStat.BigWins
 			mapStatsByIndividual["BigLosses"] += objStat.BigLosses
+			mapStatsByIndividual["WinsBy1"] += objStat.WinsBy1
+			mapStatsByIndividual["WinsBy2"] += objStat.WinsBy2
+			mapStatsByIndividual["WinsBy3Plus"] += objStat.WinsBy3Plus
+			mapStatsByIndividual["LossesBy1"] += objStat.LossesBy1
+			mapStatsByIndividual["LossesBy2"] += objStat.LossesBy2
+			mapStatsByIndividual["LossesBy3Plus"] += objStat.LossesBy3Plus
 		}
 	}
 	return mapStatsByIndividual
@@ -609,6 +936,12 @@ func getAbsoluteStatsByIndividual(records []RawData, sliceAbsoluteTeamStats []St
 			CleanSheetsAgainst: mapIndividualStats["CleanSheetsAgainst"],
 			BigWins:            mapIndividualStats["BigWins"],
 			BigLosses:          mapIndividualStats["BigLosses"],
+			WinsBy1:            mapIndividualStats["WinsBy1"],
+			WinsBy2:            mapIndividualStats["WinsBy2"],
+			WinsBy3Plus:        mapIndividualStats["WinsBy3Plus"],
+			LossesBy1:          mapIndividualStats["LossesBy1"],
+			LossesBy2:          mapIndividualStats["LossesBy2"],
+			LossesBy3Plus:      mapIndividualStats["LossesBy3Plus"],
 		}
 		sliceStatsAllIndividuals = append(sliceStatsAllIndividuals, objStatsByIndividual)
 	}
@@ -616,26 +949,27 @@ func getAbsoluteStatsByIndividual(records []RawData, sliceAbsoluteTeamStats []St
 }
 
 // Gets string of WLD (Wins, Losses, Draws) representation of `LatestForm` for Teams
-func representLatestForm(records []RawData, team string, nLatestGames int) string {
+func representLatestForm(records []RawData, team string, nLatestGames int, rules ScoringRules) string {
 	representationLatestForm := ""
 	numGamesConsidered := 0
 	for i := len(records) - 1; i >= 0; i-- {
 		match := records[i]
+		homeGoals, awayGoals := getScoreForTable(match, rules)
 		if team == match.HomeTeam {
-			if match.HomeGoals > match.AwayGoals {
+			if homeGoals > awayGoals {
 				representationLatestForm += "W"
-			} else if match.HomeGoals == match.AwayGoals {
+			} else if homeGoals == awayGoals {
 				representationLatestForm += "D"
-			} else if match.HomeGoals < match.AwayGoals {
+			} else if homeGoals < awayGoals {
 				representationLatestForm += "L"
 			}
 			numGamesConsidered++
 		} else if team == match.AwayTeam {
-			if match.AwayGoals > match.HomeGoals {
+			if awayGoals > homeGoals {
 				representationLatestForm += "W"
-			} else if match.AwayGoals == match.HomeGoals {
+			} else if awayGoals == homeGoals {
 				representationLatestForm += "D"
-			} else if match.AwayGoals < match.HomeGoals {
+			} else if awayGoals < homeGoals {
 				representationLatestForm += "L"
 			}
 			numGamesConsidered++
@@ -648,26 +982,27 @@ func representLatestForm(records []RawData, team string, nLatestGames int) strin
 }
 
 // Gets string of WLD (Wins, Losses, Draws) representation of `LatestForm` for Individuals
-func representLatestFormSolo(records []RawData, individual string, nLatestGames int) string {
+func representLatestFormSolo(records []RawData, individual string, nLatestGames int, rules ScoringRules) string {
 	representationLatestForm := ""
 	numGamesConsidered := 0
 	for i := len(records) - 1; i >= 0; i-- {
 		match := records[i]
+		homeGoals, awayGoals := getScoreForTable(match, rules)
 		if individualInTeam(individual, match.HomeTeam) {
-			if match.HomeGoals > match.AwayGoals {
+			if homeGoals > awayGoals {
 				representationLatestForm += "W"
-			} else if match.HomeGoals == match.AwayGoals {
+			} else if homeGoals == awayGoals {
 				representationLatestForm += "D"
-			} else if match.HomeGoals < match.AwayGoals {
+			} else if homeGoals < awayGoals {
 				representationLatestForm += "L"
 			}
 			numGamesConsidered++
 		} else if individualInTeam(individual, match.AwayTeam) {
-			if match.AwayGoals > match.HomeGoals {
+			if awayGoals > homeGoals {
 				representationLatestForm += "W"
-			} else if match.AwayGoals == match.HomeGoals {
+			} else if awayGoals == homeGoals {
 				representationLatestForm += "D"
-			} else if match.AwayGoals < match.HomeGoals {
+			} else if awayGoals < homeGoals {
 				representationLatestForm += "L"
 			}
 			numGamesConsidered++
@@ -680,31 +1015,23 @@ func representLatestFormSolo(records []RawData, individual string, nLatestGames
 }
 
 // Get latest PPG info for Teams. Returns info about "LatestPPG" and "NumGamesConsidered"
-func getLatestPpgInfo(records []RawData, team string, nLatestGames int) map[string]float64 {
+func getLatestPpgInfo(records []RawData, team string, nLatestGames int, rules ScoringRules) map[string]float64 {
 	mapLatestPpgInfo := map[string]float64{}
-	wins, draws, numGamesConsidered := 0, 0, 0
+	points, numGamesConsidered := 0, 0
 	for i := len(records) - 1; i >= 0; i-- {
 		match := records[i]
 		if team == match.HomeTeam {
-			if match.HomeGoals > match.AwayGoals {
-				wins++
-			} else if match.HomeGoals == match.AwayGoals {
-				draws++
-			}
+			points += getPointsFromMatch(match, true, rules)
 			numGamesConsidered++
 		} else if team == match.AwayTeam {
-			if match.AwayGoals > match.HomeGoals {
-				wins++
-			} else if match.HomeGoals == match.AwayGoals {
-				draws++
-			}
+			points += getPointsFromMatch(match, false, rules)
 			numGamesConsidered++
 		}
 		if numGamesConsidered == nLatestGames {
 			break
 		}
 	}
-	latestPPG := float64(3 * wins + draws) / float64(numGamesConsidered)
+	latestPPG := float64(points) / float64(numGamesConsidered)
 	latestPPG = round(latestPPG, 4)
 	mapLatestPpgInfo["LatestPPG"] += latestPPG
 	mapLatestPpgInfo["NumGamesConsidered"] += float64(numGamesConsidered)
@@ -712,31 +1039,23 @@ func getLatestPpgInfo(records []RawData, team string, nLatestGames int) map[stri
 }
 
 // Get latest PPG info for Individuals. Returns info about "LatestPPG" and "NumGamesConsidered"
-func getLatestPpgInfoSolo(records []RawData, individual string, nLatestGames int) map[string]float64 {
+func getLatestPpgInfoSolo(records []RawData, individual string, nLatestGames int, rules ScoringRules) map[string]float64 {
 	mapLatestPpgInfoSolo := map[string]float64{}
-	wins, draws, numGamesConsidered := 0, 0, 0
+	points, numGamesConsidered := 0, 0
 	for i := len(records) - 1; i >= 0; i-- {
 		match := records[i]
 		if individualInTeam(individual, match.HomeTeam) {
-			if match.HomeGoals > match.AwayGoals {
-				wins++
-			} else if match.HomeGoals == match.AwayGoals {
-				draws++
-			}
+			points += getPointsFromMatch(match, true, rules)
 			numGamesConsidered++
 		} else if individualInTeam(individual, match.AwayTeam) {
-			if match.AwayGoals > match.HomeGoals {
-				wins++
-			} else if match.HomeGoals == match.AwayGoals {
-				draws++
-			}
+			points += getPointsFromMatch(match, false, rules)
 			numGamesConsidered++
 		}
 		if numGamesConsidered == nLatestGames {
 			break
 		}
 	}
-	latestPPG := float64(3 * wins + draws) / float64(numGamesConsidered)
+	latestPPG := float64(points) / float64(numGamesConsidered)
 	latestPPG = round(latestPPG, 4)
 	mapLatestPpgInfoSolo["LatestPPG"] += latestPPG
 	mapLatestPpgInfoSolo["NumGamesConsidered"] += float64(numGamesConsidered)
@@ -747,15 +1066,15 @@ func getLatestPpgInfoSolo(records []RawData, individual string, nLatestGames int
 Get latest form of team/individual in last `nLatestGames` games. Metric used is PPG (Points per game).
 NOTE: Assumes that the records are sorted in ascending order of time of occurence of matches.
 */
-func getLatestForm(records []RawData, nLatestGames int) []LatestForm {
+func getLatestForm(records []RawData, nLatestGames int, rules ScoringRules) []LatestForm {
 	sliceLatestFormData := []LatestForm{}
 	teams := getUniqueTeamNames(records)
 	for _, team := range teams {
-		mapLatestPpgInfo := getLatestPpgInfo(records, team, nLatestGames)
+		mapLatestPpgInfo := getLatestPpgInfo(records, team, nLatestGames, rules)
 		tempObj := LatestForm{
 			Rank:               0,
 			Team:               team,
-			Form:               representLatestForm(records, team, nLatestGames),
+			Form:               representLatestForm(records, team, nLatestGames, rules),
 			LatestPPG:          mapLatestPpgInfo["LatestPPG"],
 			NumGamesConsidered: int(mapLatestPpgInfo["NumGamesConsidered"]),
 		}
@@ -764,15 +1083,15 @@ func getLatestForm(records []RawData, nLatestGames int) []LatestForm {
 	return sliceLatestFormData
 }
 
-func getLatestFormSolo(records []RawData, nLatestGames int) []LatestForm {
+func getLatestFormSolo(records []RawData, nLatestGames int, rules ScoringRules) []LatestForm {
 	sliceLatestFormData := []LatestForm{}
 	individuals := getUniqueIndividualNames(records)
 	for _, individual := range individuals {
-		mapLatestPpgInfoSolo := getLatestPpgInfoSolo(records, individual, nLatestGames)
+		mapLatestPpgInfoSolo := getLatestPpgInfoSolo(records, individual, nLatestGames, rules)
 		tempObj := LatestForm{
 			Rank:               0,
 			Team:               individual,
-			Form:               representLatestFormSolo(records, individual, nLatestGames),
+			Form:               representLatestFormSolo(records, individual, nLatestGames, rules),
 			LatestPPG:          mapLatestPpgInfoSolo["LatestPPG"],
 			NumGamesConsidered: int(mapLatestPpgInfoSolo["NumGamesConsidered"]),
 		}
@@ -781,13 +1100,36 @@ func getLatestFormSolo(records []RawData, nLatestGames int) []LatestForm {
 	return sliceLatestFormData
 }
 
-// Saves slice having objects of `StatsAbs` struct to CSV file
-func saveAbsToCsv(sliceData []StatsAbs, filepath string) {
-	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY, 0777)
-	defer file.Close()
+/*
+Gets ranked slice of normalized stats from slice of absolute stats, as per the given `PipelineOptions`.
+Applies shrinkage, drops teams/individuals that don't meet the minimum games played, sorts and attaches ranking.
+*/
+func getRankedNormStats(sliceAbsStats []StatsAbs, options PipelineOptions) []StatsNorm {
+	sliceNormStats := getNormalizedStats(sliceAbsStats)
+	sliceNormStats = shrinkPpgTowardsLeagueMean(sliceNormStats, sliceAbsStats, options.PriorStrength)
+	sliceNormStats = filterNormStatsByGamesPlayed(sliceNormStats, options.MinGamesPlayed)
+	if options.RankByShrunkPPG {
+		sliceNormStats = sortNormStatsByShrunkPpg(sliceNormStats)
+	} else {
+		sliceNormStats = sortNormStatsByMetric(sliceNormStats)
+	}
+	return attachRankingToNormStats(sliceNormStats)
+}
+
+// Writes slice of stringified records (first record being the header) to CSV file
+func writeStringifiedRecordsToCsv(sliceStringifiedRecords [][]string, filepath string) {
+	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0777)
 	if err != nil {
-		os.Exit(1)
+		log.Fatalln("Couldn't create the CSV file", err)
 	}
+	defer file.Close()
+	csvWriter := csv.NewWriter(file)
+	csvWriter.WriteAll(sliceStringifiedRecords)
+	csvWriter.Flush()
+}
+
+// Saves slice having objects of `StatsAbs` struct to CSV file
+func saveAbsToCsv(sliceData []StatsAbs, filepath string) {
 	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
 	statFields := structs.Names(&StatsAbs{})
 	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
@@ -795,18 +1137,11 @@ func saveAbsToCsv(sliceData []StatsAbs, filepath string) {
 		record := obj.ListStringifiedValues()
 		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
 	}
-	csvWriter := csv.NewWriter(file)
-	csvWriter.WriteAll(sliceStringifiedRecords)
-	csvWriter.Flush()
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
 }
 
 // Saves slice having objects of `StatsNorm` struct to CSV file
 func saveNormToCsv(sliceData []StatsNorm, filepath string) {
-	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY, 0777)
-	defer file.Close()
-	if err != nil {
-		os.Exit(1)
-	}
 	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
 	statFields := structs.Names(&StatsNorm{})
 	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
@@ -814,18 +1149,11 @@ func saveNormToCsv(sliceData []StatsNorm, filepath string) {
 		record := obj.ListStringifiedValues()
 		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
 	}
-	csvWriter := csv.NewWriter(file)
-	csvWriter.WriteAll(sliceStringifiedRecords)
-	csvWriter.Flush()
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
 }
 
 // Saves slice having objects of `LatestForm` struct to CSV file
 func saveLatestFormToCsv(sliceData []LatestForm, filepath string) {
-	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY, 0777)
-	defer file.Close()
-	if err != nil {
-		os.Exit(1)
-	}
 	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
 	statFields := structs.Names(&LatestForm{})
 	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
@@ -833,9 +1161,7 @@ func saveLatestFormToCsv(sliceData []LatestForm, filepath string) {
 		record := obj.ListStringifiedValues()
 		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
 	}
-	csvWriter := csv.NewWriter(file)
-	csvWriter.WriteAll(sliceStringifiedRecords)
-	csvWriter.Flush()
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
 }
 
 // Gets slice of all filenames from data source
@@ -851,72 +1177,322 @@ func getListOfDataFilenames() []string {
 	}
 	filenamesDesired := []string{}
 	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".lock") || strings.HasSuffix(file.Name(), ".tmp") {
+			continue // Left by a match being added (see `writeMatchToDataFile`)
+		}
 		filenamesDesired = append(filenamesDesired, file.Name())
 	}
 	return filenamesDesired
 }
 
-// Executes ETL pipeline for a raw data file, and stores results appropriately
-func executePipeline(filename string) {
+/*
+Executes ETL pipeline for a raw data file, and stores results appropriately.
+Returns false if the data file failed validation.
+*/
+func executePipeline(filename string, options PipelineOptions, store *ResultStore) bool {
 	filenameWithoutExt := removeExtension(filename)
 	pathRawData := pathDataFolder + "/" + filename
-	rawRecords := readRawRecordsFromCsv(pathRawData)
+	rawRecords := loadRawRecords(pathRawData, options)
 	nLatestGames := 10 // Number of latest games to consider for LatestForm
+	filters, err := parseMatchFilters(options.Filter, options.ScoringRules)
+	if err != nil {
+		log.Fatalln("Invalid -filter:", err)
+	}
+	if len(filters) > 0 {
+		filenameWithoutExt += " (Filtered)" // Results of filtered matches don't overwrite results of all matches
+	}
+	leaderboards := loadLeaderboards(options)
 
-	// Data validation - Check if `HomeTeam` name is same as `AwayTeam` name
-	if isHomeSameAsAway(rawRecords) {
-		printSameHomeAndAwayNames(rawRecords)
-		fmt.Println("Incorrect team-names for '" + filename + "'. Check your data!")
-		return
+	// Pipeline cache i.e; skip unchanged data files, and only fold appended matches into aggregate state
+	content, err := os.ReadFile(pathRawData)
+	if err != nil {
+		log.Fatalln("Couldn't read the data file", err)
+	}
+	paramsHash := getParamsHash(options, nLatestGames)
+	cache := loadPipelineCache(filename)
+	cacheStatus := cacheStale
+	if !options.NoCache && len(filters) == 0 {
+		cacheStatus = getCacheStatus(cache, content, rawRecords, paramsHash)
+	}
+	if cacheStatus == cacheUnchanged && hasAllOutputs(cache) && store == nil && len(leaderboards) == 0 {
+		fmt.Println("Skipped '" + filename + "' (unchanged since the previous run)")
+		return true
+	}
+
+	// Paths to the results saved, so that a later run can check that none is missing before skipping the data file
+	outputPaths := []string{}
+	getResultPath := func(suffix string) string {
+		path := pathResultsFolder + "/" + filenameWithoutExt + " - " + suffix
+		outputPaths = append(outputPaths, path)
+		return path
+	}
+
+	// Data validation
+	if !executeValidation(rawRecords, filename, options) {
+		return false
+	}
+	if store != nil {
+		numIngested := store.ingestMatches(filename, rawRecords)
+		fmt.Println("Ingested " + strconv.Itoa(numIngested) + " new/edited match/es of '" + filename + "' into the database")
+	}
+	allRecords := rawRecords
+	rawRecords, _ = applyMatchFilters(rawRecords, filters)
+	if len(filters) > 0 && len(rawRecords) == 0 {
+		fmt.Println("No matches of '" + filename + "' are left by -filter. Results weren't saved")
+		return true
+	}
+	// Perspective filters (i.e; home games only) split matches between their sides. Tables computed from whole matches
+	// (i.e; intervals, strength of schedule, goal distributions, brackets) can't be split, so they aren't saved with those
+	hasPerspective := hasPerspectiveFilters(filters)
+	state, recordsToFold := newAggregateState(), rawRecords
+	if cacheStatus != cacheStale {
+		state, recordsToFold = cache.State, rawRecords[cache.NumRecords:]
+	}
+	for _, record := range recordsToFold {
+		state.foldRecord(record, nLatestGames, options.ScoringRules)
+	}
+	if cacheStatus == cacheAppended {
+		fmt.Println("Folded " + strconv.Itoa(len(recordsToFold)) + " appended match/es of '" + filename + "'")
 	}
 
 	// ########## Teams stats ##########
-	sliceAbsStats := getAbsoluteStats(rawRecords)
-	sliceNormStats := getNormalizedStats(sliceAbsStats)
+	sliceAbsStats := state.getAbsoluteStats()
+	if hasPerspective {
+		sliceAbsStats = getAbsoluteStatsFiltered(allRecords, filters, options.ScoringRules)
+		if len(sliceAbsStats) == 0 {
+			fmt.Println("No matches of '" + filename + "' are left by -filter. Results weren't saved")
+			return true
+		}
+	}
+	sliceNormStats := getRankedNormStats(sliceAbsStats, options)
 	sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
 	sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
-	sliceNormStats = sortNormStatsByMetric(sliceNormStats)
-	sliceNormStats = attachRankingToNormStats(sliceNormStats)
 	// LatestForm
-	sliceLatestForm := getLatestForm(rawRecords, nLatestGames)
+	sliceLatestForm := state.getLatestForm(false)
+	if hasPerspective {
+		sliceLatestForm = getLatestFormFiltered(allRecords, filters, nLatestGames, false, options.ScoringRules)
+	}
 	sliceLatestForm = sortLatestFormByMetric(sliceLatestForm)
 	sliceLatestForm = attachRankingToLatestForm(sliceLatestForm)
 	// Save results
-	saveAbsToCsv(sliceAbsStats, pathResultsFolder + "/" + filenameWithoutExt + " - Teams - Absolute Stats.csv")
-	saveNormToCsv(sliceNormStats, pathResultsFolder + "/" + filenameWithoutExt + " - Teams - Normalized Stats.csv")
-	saveLatestFormToCsv(sliceLatestForm, pathResultsFolder + "/" + filenameWithoutExt + " - Teams - Latest Form.csv")
+	saveAbsToCsv(sliceAbsStats, getResultPath("Teams - Absolute Stats.csv"))
+	saveNormToCsv(sliceNormStats, getResultPath("Teams - Normalized Stats.csv"))
+	saveLatestFormToCsv(sliceLatestForm, getResultPath("Teams - Latest Form.csv"))
+	if store != nil {
+		store.saveStats(filename, entityTeam, sliceAbsStats, sliceNormStats, sliceLatestForm)
+	}
+	if options.Intervals && !hasPerspective {
+		sliceNormStatsIntervals := getNormStatsIntervals(rawRecords, sliceAbsStats, sliceNormStats, options, false)
+		saveNormIntervalsToCsv(sliceNormStatsIntervals, getResultPath("Teams - Normalized Stats Intervals.csv"))
+	}
+	// Strength of schedule
+	if !hasPerspective {
+		sliceStrengthOfSchedule := getStrengthOfSchedule(rawRecords, sliceAbsStats, state.MapEloByTeam)
+		sliceStrengthOfSchedule = sortAndRankStrengthOfSchedule(sliceStrengthOfSchedule)
+		saveStrengthOfScheduleToCsv(sliceStrengthOfSchedule, getResultPath("Teams - Strength of Schedule.csv"))
+	}
+	// Luck (Pythagorean expectation)
+	sliceLuck, pythagoreanExponent := getRankedLuck(sliceAbsStats, options)
+	saveLuckToCsv(sliceLuck, getResultPath("Teams - Luck.csv"))
+	fmt.Println("Pythagorean exponent used for teams of '" + filename + "': " + strconv.FormatFloat(pythagoreanExponent, 'f', 3, 64))
+	// Goal distributions (scorelines, goals per match, and Poisson fit)
+	if !hasPerspective {
+		sliceGoalDistributions, sliceScorelineFrequencies, sliceGoalsPerMatchFrequencies := getGoalDistributions(rawRecords, options.ScoringRules)
+		saveGoalDistributionsToCsv(sliceGoalDistributions, getResultPath("Teams - Goal Distribution.csv"))
+		saveScorelineFrequenciesToCsv(sliceScorelineFrequencies, getResultPath("Teams - Scorelines.csv"))
+		saveGoalsPerMatchFrequenciesToCsv(sliceGoalsPerMatchFrequencies, getResultPath("Teams - Goals Per Match.csv"))
+	}
+	// Clinch statuses (for data files having fixtures yet to be played)
+	remainingFixtures := loadRemainingFixtures(pathRawData, options)
+	if len(remainingFixtures) > 0 && len(filters) == 0 {
+		bands := parsePositionBands(options.Bands, len(getUniqueTeamNames(append(rawRecords, remainingFixtures...))), options.NumRelegated)
+		sliceClinchStatuses := getClinchStatuses(sliceAbsStats, remainingFixtures, bands, options.NumRelegated, options.MaxSearchNodes)
+		saveClinchStatusesToCsv(sliceClinchStatuses, bands, getResultPath("Teams - Clinch Status.csv"))
+	}
+	// Half-time stats and half-time table
+	if hasHalfTimeData(rawRecords) && !hasPerspective {
+		sliceHalfTimeStats := getHalfTimeStats(rawRecords, options.ScoringRules)
+		sliceHalfTimeStats = sortAndRankHalfTimeStats(sliceHalfTimeStats)
+		sliceHalfTimeTable := getAbsoluteStats(getHalfTimeRecords(rawRecords), options.ScoringRules)
+		sliceHalfTimeTable = sortAbsStatsByMetric(sliceHalfTimeTable)
+		sliceHalfTimeTable = attachRankingToAbsStats(sliceHalfTimeTable)
+		saveHalfTimeStatsToCsv(sliceHalfTimeStats, getResultPath("Teams - Half-Time Stats.csv"))
+		saveAbsToCsv(sliceHalfTimeTable, getResultPath("Teams - Half-Time Table.csv"))
+	}
+	// Match statistics (shots, xG, cards, corners)
+	if hasMatchStatistics(rawRecords) && !hasPerspective {
+		sliceMatchStatsAbs := getMatchStatsAbs(rawRecords, options.ScoringRules)
+		sliceMatchStatsNorm := getMatchStatsNorm(sliceMatchStatsAbs)
+		sliceMatchStatsAbs = sortAndRankMatchStatsAbs(sliceMatchStatsAbs)
+		sliceMatchStatsNorm = sortAndRankMatchStatsNorm(sliceMatchStatsNorm)
+		saveMatchStatsAbsToCsv(sliceMatchStatsAbs, getResultPath("Teams - Match Stats Absolute.csv"))
+		saveMatchStatsNormToCsv(sliceMatchStatsNorm, getResultPath("Teams - Match Stats Normalized.csv"))
+	}
+	// Group tables and tournament bracket (for tournaments having group stages), or else knockout bracket
+	if hasGroupStages(rawRecords) && !hasPerspective {
+		sliceGroupStandings, sliceQualifiers := getGroupStandings(rawRecords, options.QualifiersPerGroup, options.BestNextPlaced, options.ScoringRules)
+		sliceBracketMatches := getTournamentBracket(rawRecords, sliceQualifiers)
+		saveGroupStandingsToCsv(sliceGroupStandings, getResultPath("Group Tables.csv"))
+		saveTournamentBracketToCsv(sliceBracketMatches, getResultPath("Tournament Bracket.csv"))
+		saveTournamentBracketToHtml(sliceBracketMatches, filenameWithoutExt, getResultPath("Tournament Bracket.html"))
+	} else if hasKnockoutData(rawRecords) && !hasPerspective {
+		sliceKnockoutTies := getKnockoutBracket(rawRecords)
+		saveKnockoutBracketToCsv(sliceKnockoutTies, getResultPath("Knockout Bracket.csv"))
+	}
+	// Custom leaderboards
+	for _, leaderboard := range leaderboards {
+		writeStringifiedRecordsToCsv(getLeaderboardRecords(leaderboard, sliceAbsStats, options), getResultPath("Teams - Leaderboard - " + leaderboard.Name + ".csv"))
+	}
 	fmt.Println("Computed teams' stats for '" + filename + "'")
 
 	// ########## Individuals' stats ##########
 	if filenameContains2v2(filename) && isValid2v2Naming(rawRecords) {
 		sliceAbsStatsSolo := getAbsoluteStatsByIndividual(rawRecords, sliceAbsStats)
-		sliceNormStatsSolo := getNormalizedStats(sliceAbsStatsSolo)
+		sliceAbsStatsSolo = removeStatsWithoutGames(sliceAbsStatsSolo)
+		sliceNormStatsSolo := getRankedNormStats(sliceAbsStatsSolo, options)
 		sliceAbsStatsSolo = sortAbsStatsByMetric(sliceAbsStatsSolo)
 		sliceAbsStatsSolo = attachRankingToAbsStats(sliceAbsStatsSolo)
-		sliceNormStatsSolo = sortNormStatsByMetric(sliceNormStatsSolo)
-		sliceNormStatsSolo = attachRankingToNormStats(sliceNormStatsSolo)
 		// LatestForm
-		sliceLatestFormSolo := getLatestFormSolo(rawRecords, nLatestGames)
+		sliceLatestFormSolo := state.getLatestForm(true)
+		if hasPerspective {
+			sliceLatestFormSolo = getLatestFormFiltered(allRecords, filters, nLatestGames, true, options.ScoringRules)
+		}
 		sliceLatestFormSolo = sortLatestFormByMetric(sliceLatestFormSolo)
 		sliceLatestFormSolo = attachRankingToLatestForm(sliceLatestFormSolo)
 		// Save results
-		saveAbsToCsv(sliceAbsStatsSolo, pathResultsFolder + "/" + filenameWithoutExt + " - Individuals - Absolute Stats.csv")
-		saveNormToCsv(sliceNormStatsSolo, pathResultsFolder + "/" + filenameWithoutExt + " - Individuals - Normalized Stats.csv")
-		saveLatestFormToCsv(sliceLatestFormSolo, pathResultsFolder + "/" + filenameWithoutExt + " - Individuals - Latest Form.csv")
+		saveAbsToCsv(sliceAbsStatsSolo, getResultPath("Individuals - Absolute Stats.csv"))
+		saveNormToCsv(sliceNormStatsSolo, getResultPath("Individuals - Normalized Stats.csv"))
+		saveLatestFormToCsv(sliceLatestFormSolo, getResultPath("Individuals - Latest Form.csv"))
+		if store != nil {
+			store.saveStats(filename, entityIndividual, sliceAbsStatsSolo, sliceNormStatsSolo, sliceLatestFormSolo)
+		}
+		if options.Intervals && !hasPerspective {
+			sliceNormStatsIntervalsSolo := getNormStatsIntervals(rawRecords, sliceAbsStatsSolo, sliceNormStatsSolo, options, true)
+			saveNormIntervalsToCsv(sliceNormStatsIntervalsSolo, getResultPath("Individuals - Normalized Stats Intervals.csv"))
+		}
+		// Luck (Pythagorean expectation)
+		sliceLuckSolo, pythagoreanExponentSolo := getRankedLuck(sliceAbsStatsSolo, options)
+		saveLuckToCsv(sliceLuckSolo, getResultPath("Individuals - Luck.csv"))
+		fmt.Println("Pythagorean exponent used for individuals of '" + filename + "': " + strconv.FormatFloat(pythagoreanExponentSolo, 'f', 3, 64))
+		// Custom leaderboards
+		for _, leaderboard := range leaderboards {
+			writeStringifiedRecordsToCsv(getLeaderboardRecords(leaderboard, sliceAbsStatsSolo, options), getResultPath("Individuals - Leaderboard - " + leaderboard.Name + ".csv"))
+		}
 		fmt.Println("Computed individuals' stats for '" + filename + "'")
 	}
 	if filenameContains2v2(filename) {
 		if !isValid2v2Naming(rawRecords) {
-			printInvalid2v2TeamNames(rawRecords)
 			fmt.Println("Incorrect team-names! Could NOT compute individuals' stats for '" + filename + "'")
 		}
 	}
+	if len(filters) == 0 {
+		cacheToSave := PipelineCache{ContentHash: getContentHash(content), Size: len(content), ParamsHash: paramsHash, NumRecords: len(rawRecords), State: state, OutputPaths: outputPaths}
+		if err := savePipelineCache(filename, cacheToSave); err != nil {
+			fmt.Println("Couldn't save the pipeline cache of '" + filename + "'", err)
+		}
+	}
+	return true
+}
+
+// Registers flags that populate `PipelineOptions` on the given flag set
+func registerPipelineFlags(flagSet *flag.FlagSet) *PipelineOptions {
+	options := &PipelineOptions{}
+	flagSet.IntVar(&options.MinGamesPlayed, "min-games", 0, "Minimum games played to qualify for the normalized rankings")
+	flagSet.Float64Var(&options.PriorStrength, "prior-strength", 0, "Number of league-average games blended into each PPG for ShrunkPPG (0 disables shrinkage)")
+	flagSet.BoolVar(&options.RankByShrunkPPG, "rank-by-shrunk-ppg", false, "Rank normalized stats by ShrunkPPG instead of PPG")
+	flagSet.BoolVar(&options.Intervals, "intervals", false, "Compute confidence intervals for normalized stats")
+	flagSet.Float64Var(&options.ConfidenceLevel, "confidence", 0.95, "Confidence level of the intervals")
+	flagSet.IntVar(&options.BootstrapSamples, "bootstrap-samples", 2000, "Number of bootstrap resamples used for intervals of per-game rates")
+	flagSet.Float64Var(&options.PythagoreanExponent, "pythagorean-exponent", 0, "Exponent used for Pythagorean expectation (0 fits the exponent to the data)")
+	flagSet.StringVar(&options.AliasesFile, "aliases", pathAliasesFile, "Path to CSV file having columns Alias, Canonical (ignored if it doesn't exist)")
+	flagSet.IntVar(&options.MaxGoals, "max-goals", 15, "Goals by one side above which a score is flagged as absurd during validation")
+	flagSet.BoolVar(&options.FailOnWarnings, "fail-on-warnings", false, "Don't compute stats of data files having validation warnings")
+	flagSet.StringVar(&options.ValidationFormat, "validation-format", "csv", "Format of validation reports (csv or json)")
+	flagSet.BoolVar(&options.ScoringRules.CountExtraTime, "extra-time", defaultScoringRules.CountExtraTime, "Use the score after extra time (instead of regulation time) in tables")
+	flagSet.StringVar(&options.ScoringRules.ShootoutMode, "shootouts", defaultScoringRules.ShootoutMode, "How penalty shootouts count in tables (draw or points)")
+	flagSet.IntVar(&options.ScoringRules.PointsForShootoutWin, "shootout-win-points", defaultScoringRules.PointsForShootoutWin, "Points for winning a shootout (if shootouts count as points)")
+	flagSet.IntVar(&options.ScoringRules.PointsForShootoutLoss, "shootout-loss-points", defaultScoringRules.PointsForShootoutLoss, "Points for losing a shootout (if shootouts count as points)")
+	flagSet.IntVar(&options.ScoringRules.BigResultGoalMargin, "big-margin", defaultScoringRules.BigResultGoalMargin, "Goal margin at or above which a win/loss counts as a big win/loss")
+	flagSet.StringVar(&options.StatColumnsFile, "stat-columns", "", "Path to CSV file having columns Column, Side, Stat (extends the default football-data.co.uk mapping)")
+	flagSet.IntVar(&options.QualifiersPerGroup, "qualifiers-per-group", 2, "Number of teams qualifying from each group of a tournament")
+	flagSet.IntVar(&options.BestNextPlaced, "best-next-placed", 0, "Number of best teams placed just below the group qualifiers that also qualify (eg: best thirds)")
+	flagSet.StringVar(&options.Bands, "bands", "Title=1", "Bands of finishing positions for clinch statuses i.e; \"Title=1,Top 4=1-4\"")
+	flagSet.IntVar(&options.NumRelegated, "relegated", 0, "Number of positions at the bottom of the table that are relegated (adds a Relegation band)")
+	flagSet.IntVar(&options.MaxSearchNodes, "max-search-nodes", 200000, "Maximum number of outcomes of remaining fixtures searched per team for clinch statuses")
+	flagSet.StringVar(&options.DatabasePath, "db", "", "Path to SQLite database storing matches and computed tables of every run (disabled if empty)")
+	flagSet.StringVar(&options.Filter, "filter", "", "Compute stats from a subset of matches i.e; \"from=2012-01-01; venue=home; opponents=top-half\"")
+	flagSet.StringVar(&options.Leaderboard, "leaderboard", "", "Query of a custom leaderboard i.e; \"rank by GSPG where GamesPlayed >= 10, show Team, GSPG, CsPct, top 5\"")
+	flagSet.StringVar(&options.LeaderboardsFile, "leaderboards", "", "Path to CSV file having columns Name, Query of saved custom leaderboards")
+	flagSet.BoolVar(&options.NoCache, "no-cache", false, "Recompute every data file from scratch, ignoring the pipeline cache")
+	flagSet.StringVar(&options.Manifest, "manifest", "", "Path to CSV file having columns Competition, Season, Filename (defaults to grouping by filename prefix)")
+	return options
+}
+
+// Checks options populated from flags (see `registerPipelineFlags`), and exits if any of them is invalid
+func checkPipelineOptions(options PipelineOptions) {
+	if options.PriorStrength < 0 {
+		log.Fatalln("Invalid -prior-strength " + strconv.FormatFloat(options.PriorStrength, 'f', -1, 64) + ". Expected a number of games of at least 0")
+	}
+	if options.ConfidenceLevel <= 0 || options.ConfidenceLevel >= 1 {
+		log.Fatalln("Invalid -confidence " + strconv.FormatFloat(options.ConfidenceLevel, 'f', -1, 64) + ". Expected a level between 0 and 1 (exclusive)")
+	}
+	if options.BootstrapSamples < 1 {
+		log.Fatalln("Invalid -bootstrap-samples " + strconv.Itoa(options.BootstrapSamples) + ". Expected at least 1 resample")
+	}
+	if options.ValidationFormat != "csv" && options.ValidationFormat != "json" {
+		log.Fatalln("Invalid -validation-format '" + options.ValidationFormat + "'. Expected 'csv' or 'json'")
+	}
+	if options.ScoringRules.ShootoutMode != shootoutModeDraw && options.ScoringRules.ShootoutMode != shootoutModePoints {
+		log.Fatalln("Invalid -shootouts '" + options.ScoringRules.ShootoutMode + "'. Expected '" + shootoutModeDraw + "' or '" + shootoutModePoints + "'")
+	}
+	if rules := options.ScoringRules; rules.PointsForShootoutLoss < 0 || rules.PointsForShootoutWin < rules.PointsForShootoutLoss || rules.PointsForShootoutWin > 3 {
+		log.Fatalln("Invalid shootout points. Expected 0 <= -shootout-loss-points <= -shootout-win-points <= 3")
+	}
+	if options.ScoringRules.BigResultGoalMargin < 1 {
+		log.Fatalln("Invalid -big-margin " + strconv.Itoa(options.ScoringRules.BigResultGoalMargin) + ". Expected a goal margin of at least 1")
+	}
+}
+
+// Subcommands by name. Running without a subcommand computes results for all data files
+var subcommands = map[string]func(args []string){
+	"add":       runAddCommand,
+	"aliases":   runAliasesCommand,
+	"diff":      runDiffCommand,
+	"fixtures":  runFixturesCommand,
+	"matchmake": runMatchmakeCommand,
+	"report":    runReportCommand,
+	"serve":     runServeCommand,
+	"watch":     runWatchCommand,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			subcommand(os.Args[2:])
+			return
+		}
+	}
+	options := registerPipelineFlags(flag.CommandLine)
+	flag.Parse()
+	checkPipelineOptions(*options)
+	var store *ResultStore
+	if options.DatabasePath != "" {
+		store = openResultStore(options.DatabasePath, *options)
+	}
 	filenames := getListOfDataFilenames()
+	numFilesFailed := 0
 	for _, filename := range filenames {
-		executePipeline(filename)
+		if !executePipeline(filename, *options, store) {
+			numFilesFailed++
+		}
+	}
+	executeAggregationPipeline(filenames, *options)
+	if store != nil {
+		store.close()
 	}
 	fmt.Println("\nDone!")
+	if numFilesFailed > 0 {
+		fmt.Println(strconv.Itoa(numFilesFailed) + " data file/s failed validation")
+		os.Exit(1)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Struct to store a band of finishing positions i.e; "Top 4" is positions 1 to 4
+type PositionBand struct {
+	Name    string
+	Highest int // Best position of the band (i.e; 1)
+	Lowest  int // Worst position of the band (i.e; 4)
+}
+
+// Constants - Statuses of a team with respect to a band of finishing positions
+const (
+	statusClinched   = "clinched"   // Finishes within the band, whatever the remaining results
+	statusEliminated = "eliminated" // Can't finish within the band, whatever the remaining results
+	statusAlive      = "alive"
+)
+
+// Struct to store the range of finishing positions a team can still reach, and its status per band of positions
+type ClinchStatus struct {
+	Rank              int
+	Team              string
+	GamesPlayed       int
+	Points            int
+	GamesRemaining    int
+	MaxPoints         int
+	BestPosition      int  // Ties on points are broken in the team's favour
+	WorstPosition     int  // Ties on points are broken against the team
+	Exact             bool // False if the search was cut short, in which case positions are the best/worst found so far
+	SafetyMagicNumber int  // Points that guarantee finishing above the relegation band, whatever the other results. -1 if not applicable
+	SafetyInOwnHands  bool // False if `SafetyMagicNumber` is more than the points left to play for i.e; safety also depends on other results
+	Statuses          []string
+}
+
+/*
+Method that gets slice of stringified elements of `ClinchStatus` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the columns given by `getClinchStatusFields`.
+Used as helper function in storing data of `ClinchStatus` struct to CSV file.
+*/
+func (obj ClinchStatus) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, strconv.Itoa(obj.Points))
+	values = append(values, strconv.Itoa(obj.GamesRemaining))
+	values = append(values, strconv.Itoa(obj.MaxPoints))
+	values = append(values, strconv.Itoa(obj.BestPosition))
+	values = append(values, strconv.Itoa(obj.WorstPosition))
+	values = append(values, strconv.FormatBool(obj.Exact))
+	values = append(values, strconv.Itoa(obj.SafetyMagicNumber))
+	values = append(values, strconv.FormatBool(obj.SafetyInOwnHands))
+	values = append(values, obj.Statuses...)
+	return values
+}
+
+// Gets column names of the clinch status table i.e; attributes of `ClinchStatus`, with a column of statuses per band
+func getClinchStatusFields(bands []PositionBand) []string {
+	fields := []string{
+		"Rank", "Team", "GamesPlayed", "Points", "GamesRemaining", "MaxPoints",
+		"BestPosition", "WorstPosition", "Exact", "SafetyMagicNumber", "SafetyInOwnHands",
+	}
+	for _, band := range bands {
+		fields = append(fields, band.Name)
+	}
+	return fields
+}
+
+/*
+Reads fixtures yet to be played (rows having empty goals) from a data file, as `RawData` records without goals.
+Team-names are canonicalised using the alias file given in `PipelineOptions`.
+*/
+func loadRemainingFixtures(filepath string, options PipelineOptions) []RawData {
+	csvfile, err := os.Open(filepath)
+	if err != nil {
+		log.Fatalln("Couldn't open the CSV file", err)
+	}
+	defer csvfile.Close()
+	r := csv.NewReader(csvfile)
+	lineCount := 0
+	fixtures := []RawData{}
+	for {
+		lineCount++
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if lineCount != 1 && record[1] == "" && record[2] == "" {
+			fixtures = append(fixtures, RawData{HomeTeam: record[0], AwayTeam: record[3]})
+		}
+	}
+	return canonicaliseTeamNames(fixtures, readAliases(options.AliasesFile))
+}
+
+/*
+Parses bands of finishing positions from a string like "Title=1,Top 4=1-4". Adds a "Relegation" band having the
+last `numRelegated` positions of a table of `numTeams` teams, if `numRelegated` is positive.
+*/
+func parsePositionBands(bands string, numTeams int, numRelegated int) []PositionBand {
+	slicePositionBands := []PositionBand{}
+	for _, band := range splitNames(bands) {
+		parts := strings.SplitN(band, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalln("Invalid band '" + band + "'. Expected the format 'Name=Position' or 'Name=Highest-Lowest'")
+		}
+		positions := strings.SplitN(parts[1], "-", 2)
+		highest, errHighest := strconv.Atoi(strings.TrimSpace(positions[0]))
+		lowest, errLowest := highest, error(nil)
+		if len(positions) == 2 {
+			lowest, errLowest = strconv.Atoi(strings.TrimSpace(positions[1]))
+		}
+		if errHighest != nil || errLowest != nil || highest < 1 || lowest < highest {
+			log.Fatalln("Invalid positions of band '" + band + "'")
+		}
+		slicePositionBands = append(slicePositionBands, PositionBand{Name: strings.TrimSpace(parts[0]), Highest: highest, Lowest: lowest})
+	}
+	if numRelegated > 0 {
+		slicePositionBands = append(slicePositionBands, PositionBand{Name: "Relegation", Highest: numTeams - numRelegated + 1, Lowest: numTeams})
+	}
+	return slicePositionBands
+}
+
+// Struct to store state of the search over outcomes of remaining fixtures, where teams are referred to by index
+type outcomeSearch struct {
+	fixtures      [][2]int
+	points        []int
+	numNodes      int
+	maxNodes      int
+	cutShort      bool
+	fewestAbove   int // Best found while minimising the number of teams above a threshold
+	mostAtOrAbove int // Best found while maximising the number of teams at or above a threshold
+}
+
+/*
+Gets outcomes of a fixture as points won by (home, away) sides, in the order they are worth searching first.
+If `favourHome` is true, the home win is tried before the away win. Draws are tried first if `drawFirst` is true,
+and last otherwise.
+*/
+func getOrderedOutcomes(favourHome bool, drawFirst bool) [][2]int {
+	outcomes := [][2]int{{3, 0}, {0, 3}}
+	if !favourHome {
+		outcomes = [][2]int{{0, 3}, {3, 0}}
+	}
+	if drawFirst {
+		return append([][2]int{{1, 1}}, outcomes...)
+	}
+	return append(outcomes, [2]int{1, 1})
+}
+
+/*
+Gets maximum flow from source to sink of a graph given by its capacity matrix (Edmonds-Karp i.e; shortest
+augmenting paths found by breadth-first search). NOTE: Modifies the capacity matrix into the residual graph.
+*/
+func getMaxFlow(capacity [][]int, source int, sink int) int {
+	flow := 0
+	for {
+		parent := make([]int, len(capacity))
+		for idx := range parent {
+			parent[idx] = -1
+		}
+		parent[source] = source
+		queue := []int{source}
+		for len(queue) > 0 && parent[sink] == -1 {
+			node := queue[0]
+			queue = queue[1:]
+			for next, residual := range capacity[node] {
+				if residual > 0 && parent[next] == -1 {
+					parent[next] = node
+					queue = append(queue, next)
+				}
+			}
+		}
+		if parent[sink] == -1 {
+			return flow
+		}
+		bottleneck := -1
+		for node := sink; node != source; node = parent[node] {
+			if bottleneck == -1 || capacity[parent[node]][node] < bottleneck {
+				bottleneck = capacity[parent[node]][node]
+			}
+		}
+		for node := sink; node != source; node = parent[node] {
+			capacity[parent[node]][node] -= bottleneck
+			capacity[node][parent[node]] += bottleneck
+		}
+		flow += bottleneck
+	}
+}
+
+/*
+Returns false if the fixtures can't possibly be played out with every team in `capped` ending on at most `threshold`
+points. Checked by max-flow on a relaxation where each fixture hands out 2 points split in any way, which needs no
+more points per team than real results (where a win hands out 3 points, and a draw 1 point each). So if the
+relaxation is infeasible, so are real results. The converse doesn't hold, so true means "maybe".
+*/
+func canStayAtMost(fixtures [][2]int, points []int, capped []bool, threshold int) bool {
+	numTeams := len(points)
+	source, sink := 0, 1+len(fixtures)+numTeams
+	capacity := make([][]int, sink+1)
+	for idx := range capacity {
+		capacity[idx] = make([]int, sink+1)
+	}
+	for idxFixture, fixture := range fixtures {
+		capacity[source][1+idxFixture] = 2
+		capacity[1+idxFixture][1+len(fixtures)+fixture[0]] = 2
+		capacity[1+idxFixture][1+len(fixtures)+fixture[1]] = 2
+	}
+	for team := 0; team < numTeams; team++ {
+		spare := 2 * len(fixtures) // Uncapped teams can take any number of points
+		if capped[team] {
+			spare = threshold - points[team]
+			if spare < 0 {
+				return false
+			}
+		}
+		capacity[1+len(fixtures)+team][sink] = spare
+	}
+	return getMaxFlow(capacity, source, sink) == 2*len(fixtures)
+}
+
+/*
+Searches outcomes of fixtures from `idx` onwards for the fewest teams ending above `threshold` points (branch and bound).
+Teams above the threshold are counted by `numAbove`, and `isAbove` tracks them.
+*/
+func (search *outcomeSearch) searchFewestAbove(idx int, threshold int, numAbove int, isAbove []bool) {
+	search.numNodes++
+	if search.numNodes > search.maxNodes {
+		search.cutShort = true
+		return
+	}
+	if numAbove >= search.fewestAbove {
+		return
+	}
+	if idx == len(search.fixtures) {
+		search.fewestAbove = numAbove
+		return
+	}
+	capped := make([]bool, len(isAbove))
+	for team := range isAbove {
+		capped[team] = !isAbove[team]
+	}
+	if numAbove+1 >= search.fewestAbove && !canStayAtMost(search.fixtures[idx:], search.points, capped, threshold) {
+		return // At least one more team must go above the threshold, which can't beat the best found
+	}
+	home, away := search.fixtures[idx][0], search.fixtures[idx][1]
+	// Draws hand out the fewest points, and wins are best given to the side furthest below the threshold
+	for _, outcome := range getOrderedOutcomes(search.points[home] <= search.points[away], true) {
+		search.points[home] += outcome[0]
+		search.points[away] += outcome[1]
+		wasAboveHome, wasAboveAway := isAbove[home], isAbove[away]
+		newlyAbove := 0
+		for _, team := range []int{home, away} {
+			if !isAbove[team] && search.points[team] > threshold {
+				isAbove[team] = true
+				newlyAbove++
+			}
+		}
+		search.searchFewestAbove(idx+1, threshold, numAbove+newlyAbove, isAbove)
+		isAbove[home], isAbove[away] = wasAboveHome, wasAboveAway
+		search.points[home] -= outcome[0]
+		search.points[away] -= outcome[1]
+	}
+}
+
+/*
+Searches outcomes of fixtures from `idx` onwards for the most teams ending at or above `threshold` points (branch and
+bound). `gamesLeft` has the number of fixtures from `idx` onwards of each team.
+*/
+func (search *outcomeSearch) searchMostAtOrAbove(idx int, threshold int, gamesLeft []int) {
+	search.numNodes++
+	if search.numNodes > search.maxNodes {
+		search.cutShort = true
+		return
+	}
+	numAtOrAbove, bound := 0, 0
+	for team, points := range search.points {
+		if points >= threshold {
+			numAtOrAbove++
+		}
+		if points+3*gamesLeft[team] >= threshold {
+			bound++
+		}
+	}
+	if bound <= search.mostAtOrAbove {
+		return
+	}
+	if idx == len(search.fixtures) {
+		search.mostAtOrAbove = numAtOrAbove
+		return
+	}
+	home, away := search.fixtures[idx][0], search.fixtures[idx][1]
+	gamesLeft[home]--
+	gamesLeft[away]--
+	// Wins are best given to the side closest below the threshold, as sides at or above it need no more points
+	homeDeficit, awayDeficit := threshold-search.points[home], threshold-search.points[away]
+	favourHome := awayDeficit <= 0 || (homeDeficit > 0 && homeDeficit <= awayDeficit)
+	for _, outcome := range getOrderedOutcomes(favourHome, false) {
+		search.points[home] += outcome[0]
+		search.points[away] += outcome[1]
+		search.searchMostAtOrAbove(idx+1, threshold, gamesLeft)
+		search.points[home] -= outcome[0]
+		search.points[away] -= outcome[1]
+	}
+	gamesLeft[home]++
+	gamesLeft[away]++
+}
+
+/*
+Gets best and worst finishing positions that a team (by index) can still reach, given points of all teams and
+remaining fixtures. For the best position, the team wins all its remaining fixtures, and ties on points go its way.
+For the worst position, it loses all of them, and ties go against it. Other fixtures are searched exhaustively.
+Returns best and worst positions found, lower bound of the best and upper bound of the worst position (equal to the
+positions found if the search wasn't cut short), and whether the search was cut short.
+*/
+func getPositionRange(team int, points []int, fixtures [][2]int, maxNodes int) (int, int, int, int, bool) {
+	numTeams := len(points)
+	// Best case: the team wins all its remaining fixtures
+	bestPoints := append([]int{}, points...)
+	otherFixtures := [][2]int{}
+	for _, fixture := range fixtures {
+		if fixture[0] == team || fixture[1] == team {
+			bestPoints[team] += 3
+		} else {
+			otherFixtures = append(otherFixtures, fixture)
+		}
+	}
+	threshold := bestPoints[team]
+	bestPoints[team] = -1 // Excluded from the count of teams above
+	isAbove := make([]bool, numTeams)
+	numAbove := 0
+	for idx, points := range bestPoints {
+		if points > threshold {
+			isAbove[idx] = true
+			numAbove++
+		}
+	}
+	searchBest := &outcomeSearch{fixtures: otherFixtures, points: bestPoints, maxNodes: maxNodes, fewestAbove: numTeams}
+	searchBest.searchFewestAbove(0, threshold, numAbove, isAbove)
+	bestPositionLowerBound := 1 + numAbove
+	if !searchBest.cutShort {
+		bestPositionLowerBound = 1 + searchBest.fewestAbove
+	}
+
+	// Worst case: the team loses all its remaining fixtures
+	worstPoints := append([]int{}, points...)
+	gamesLeft := make([]int, numTeams)
+	for _, fixture := range fixtures {
+		if fixture[0] == team {
+			worstPoints[fixture[1]] += 3
+		} else if fixture[1] == team {
+			worstPoints[fixture[0]] += 3
+		} else {
+			gamesLeft[fixture[0]]++
+			gamesLeft[fixture[1]]++
+		}
+	}
+	threshold = worstPoints[team]
+	worstPoints[team] = threshold - 3*len(fixtures) - 1 // Excluded from the count of teams at or above
+	worstPositionUpperBound := 0
+	for idx, points := range worstPoints {
+		if points+3*gamesLeft[idx] >= threshold {
+			worstPositionUpperBound++
+		}
+	}
+	worstPositionUpperBound++
+	searchWorst := &outcomeSearch{fixtures: otherFixtures, points: worstPoints, maxNodes: maxNodes, mostAtOrAbove: -1}
+	searchWorst.searchMostAtOrAbove(0, threshold, gamesLeft)
+	if !searchWorst.cutShort {
+		worstPositionUpperBound = 1 + searchWorst.mostAtOrAbove
+	}
+	cutShort := searchBest.cutShort || searchWorst.cutShort
+	return 1 + searchBest.fewestAbove, 1 + searchWorst.mostAtOrAbove, bestPositionLowerBound, worstPositionUpperBound, cutShort
+}
+
+/*
+Gets points a team (by index) needs from its remaining fixtures to guarantee finishing above the last `numRelegated`
+positions, whatever the other results. Rivals are assumed to win all their remaining fixtures (ignoring that they
+take points off each other), so the number guarantees safety but may overstate what is needed. It may be more than
+the points the team has left to play for, in which case the team can't guarantee safety by its own results.
+*/
+func getSafetyMagicNumber(team int, points []int, gamesRemaining []int, numRelegated int) int {
+	maxPointsOfRivals := []int{}
+	for idx := range points {
+		if idx != team {
+			maxPointsOfRivals = append(maxPointsOfRivals, points[idx]+3*gamesRemaining[idx])
+		}
+	}
+	sort.Ints(maxPointsOfRivals)
+	// Finishing strictly above `numRelegated` rivals keeps the team out of the relegation band
+	magicNumber := maxPointsOfRivals[numRelegated-1] + 1 - points[team]
+	if magicNumber < 0 {
+		return 0
+	}
+	return magicNumber
+}
+
+/*
+Gets clinch status of teams i.e; the range of finishing positions each team can still reach, and whether it has
+clinched/been eliminated from each band of positions. Points of remaining fixtures are 3 for a win and 1 for a draw.
+Returns slice wherein each element of the slice is an object of the struct `ClinchStatus`, ranked by points
+*/
+func getClinchStatuses(sliceAbsStats []StatsAbs, remainingFixtures []RawData, bands []PositionBand, numRelegated int, maxNodes int) []ClinchStatus {
+	teams := []string{}
+	mapIndexByTeam := map[string]int{}
+	points := []int{}
+	for _, obj := range sliceAbsStats {
+		mapIndexByTeam[obj.Team] = len(teams)
+		teams = append(teams, obj.Team)
+		points = append(points, obj.Points)
+	}
+	for _, fixture := range remainingFixtures {
+		for _, team := range []string{fixture.HomeTeam, fixture.AwayTeam} {
+			if _, ok := mapIndexByTeam[team]; !ok {
+				mapIndexByTeam[team] = len(teams)
+				teams = append(teams, team)
+				points = append(points, 0)
+			}
+		}
+	}
+	fixtures := [][2]int{}
+	gamesRemaining := make([]int, len(teams))
+	for _, fixture := range remainingFixtures {
+		home, away := mapIndexByTeam[fixture.HomeTeam], mapIndexByTeam[fixture.AwayTeam]
+		fixtures = append(fixtures, [2]int{home, away})
+		gamesRemaining[home]++
+		gamesRemaining[away]++
+	}
+	sliceClinchStatuses := []ClinchStatus{}
+	for idx, team := range teams {
+		best, worst, bestLowerBound, worstUpperBound, cutShort := getPositionRange(idx, points, fixtures, maxNodes)
+		tempObj := ClinchStatus{
+			Team:              team,
+			Points:            points[idx],
+			GamesRemaining:    gamesRemaining[idx],
+			MaxPoints:         points[idx] + 3*gamesRemaining[idx],
+			BestPosition:      best,
+			WorstPosition:     worst,
+			Exact:             !cutShort,
+			SafetyMagicNumber: -1,
+		}
+		if idx < len(sliceAbsStats) {
+			tempObj.GamesPlayed = sliceAbsStats[idx].GamesPlayed
+		}
+		if numRelegated > 0 && numRelegated < len(teams) {
+			tempObj.SafetyMagicNumber = getSafetyMagicNumber(idx, points, gamesRemaining, numRelegated)
+			tempObj.SafetyInOwnHands = tempObj.SafetyMagicNumber <= 3*gamesRemaining[idx]
+		}
+		for _, band := range bands {
+			status := statusAlive
+			if bestLowerBound > band.Lowest || worstUpperBound < band.Highest {
+				status = statusEliminated
+			} else if bestLowerBound >= band.Highest && worstUpperBound <= band.Lowest {
+				status = statusClinched
+			}
+			tempObj.Statuses = append(tempObj.Statuses, status)
+		}
+		sliceClinchStatuses = append(sliceClinchStatuses, tempObj)
+	}
+	sort.SliceStable(sliceClinchStatuses, func(i, j int) bool {
+		return sliceClinchStatuses[i].Points > sliceClinchStatuses[j].Points
+	})
+	for idx := range sliceClinchStatuses {
+		sliceClinchStatuses[idx].Rank = idx + 1
+	}
+	return sliceClinchStatuses
+}
+
+// Saves slice having objects of `ClinchStatus` struct to CSV file
+func saveClinchStatusesToCsv(sliceData []ClinchStatus, bands []PositionBand, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	sliceStringifiedRecords = append(sliceStringifiedRecords, getClinchStatusFields(bands))
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
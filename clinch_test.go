@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestSafetyMagicNumberBeyondPointsLeftIsNotInOwnHands(t *testing.T) {
+	remainingFixtures := []RawData{
+		{HomeTeam: "Wolves", AwayTeam: "Arsenal"},
+		{HomeTeam: "Burnley", AwayTeam: "Wolves"},
+	}
+	testCases := []struct {
+		wolvesPoints    int
+		wantMagicNumber int
+		wantInOwnHands  bool
+	}{
+		// Chelsea can't get more than 25 points, so Wolves need 26
+		{5, 21, false},
+		{20, 6, true},
+		{24, 2, true},
+		{26, 0, true},
+	}
+	for _, testCase := range testCases {
+		sliceAbsStats := []StatsAbs{
+			{Team: "Arsenal", Points: 30},
+			{Team: "Burnley", Points: 28},
+			{Team: "Chelsea", Points: 25},
+			{Team: "Wolves", Points: testCase.wolvesPoints},
+		}
+		for _, obj := range getClinchStatuses(sliceAbsStats, remainingFixtures, nil, 1, 100000) {
+			if obj.Team != "Wolves" {
+				continue
+			}
+			if obj.SafetyMagicNumber != testCase.wantMagicNumber || obj.SafetyInOwnHands != testCase.wantInOwnHands {
+				t.Errorf("Wolves on %d points: got magic number %d (in own hands: %v), want %d (%v)", testCase.wolvesPoints,
+					obj.SafetyMagicNumber, obj.SafetyInOwnHands, testCase.wantMagicNumber, testCase.wantInOwnHands)
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/structs"
+)
+
+// Struct to store the standing of a team in a computed table, as compared by the diff
+type TableEntry struct {
+	Rank        int
+	Points      int
+	GamesPlayed int
+	PPG         float64
+	Form        string
+}
+
+// Struct to store the change in standing of a team between two computed tables ("before" and "after")
+type TableDiff struct {
+	Team           string
+	PreviousRank   int // 0 if the team isn't in the "before" table
+	Rank           int // 0 if the team isn't in the "after" table
+	RankMovement   int // Positive if the team moved up
+	PreviousPoints int
+	Points         int
+	PointsDelta    int
+	PreviousPPG    float64
+	PPG            float64
+	PPGDelta       float64
+	PreviousForm   string
+	Form           string
+}
+
+/*
+Method that gets slice of stringified elements of `TableDiff` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `TableDiff` struct to CSV file.
+*/
+func (obj TableDiff) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.PreviousRank))
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, strconv.Itoa(obj.RankMovement))
+	values = append(values, strconv.Itoa(obj.PreviousPoints))
+	values = append(values, strconv.Itoa(obj.Points))
+	values = append(values, strconv.Itoa(obj.PointsDelta))
+	values = append(values, fmt.Sprintf("%g", obj.PreviousPPG))
+	values = append(values, fmt.Sprintf("%g", obj.PPG))
+	values = append(values, fmt.Sprintf("%g", obj.PPGDelta))
+	values = append(values, obj.PreviousForm)
+	values = append(values, obj.Form)
+	return values
+}
+
+/*
+Gets table entries of teams (or individuals, if `solo` is true) computed from `RawData` records, ranked the same way
+as the absolute stats, along with their latest form
+*/
+func getTableEntries(records []RawData, solo bool, rules ScoringRules) map[string]TableEntry {
+	nLatestGames := 10
+	sliceAbsStats := getAbsoluteStats(records, rules)
+	sliceLatestForm := []LatestForm{}
+	if solo {
+		sliceAbsStats = getAbsoluteStatsByIndividual(records, sliceAbsStats)
+		sliceLatestForm = getLatestFormSolo(records, nLatestGames, rules)
+	} else {
+		sliceLatestForm = getLatestForm(records, nLatestGames, rules)
+	}
+	sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
+	sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
+	mapTableEntryByTeam := map[string]TableEntry{}
+	for _, obj := range sliceAbsStats {
+		mapTableEntryByTeam[obj.Team] = TableEntry{
+			Rank:        obj.Rank,
+			Points:      obj.Points,
+			GamesPlayed: obj.GamesPlayed,
+			PPG:         round(float64(obj.Points)/float64(obj.GamesPlayed), 4),
+		}
+	}
+	for _, obj := range sliceLatestForm {
+		tableEntry := mapTableEntryByTeam[obj.Team]
+		tableEntry.Form = obj.Form
+		mapTableEntryByTeam[obj.Team] = tableEntry
+	}
+	return mapTableEntryByTeam
+}
+
+// Reads a CSV file saved in the results folder, as a slice of maps of column name -> value
+func readResultsCsv(filepath string) []map[string]string {
+	csvfile, err := os.Open(filepath)
+	if err != nil {
+		log.Fatalln("Couldn't open the CSV file", err)
+	}
+	defer csvfile.Close()
+	rows, err := csv.NewReader(csvfile).ReadAll()
+	if err != nil {
+		log.Fatalln("Couldn't read '"+filepath+"'", err)
+	}
+	sliceRows := []map[string]string{}
+	for idx := 1; idx < len(rows); idx++ {
+		mapValueByColumn := map[string]string{}
+		for idxColumn, column := range rows[0] {
+			if idxColumn < len(rows[idx]) {
+				mapValueByColumn[column] = rows[idx][idxColumn]
+			}
+		}
+		sliceRows = append(sliceRows, mapValueByColumn)
+	}
+	return sliceRows
+}
+
+/*
+Gets table entries of teams (or individuals, if `solo` is true) from absolute stats and latest form previously saved
+to a results folder, for the data file `filename`
+*/
+func readTableEntriesFromResults(folder string, filename string, solo bool) map[string]TableEntry {
+	entity := "Teams"
+	if solo {
+		entity = "Individuals"
+	}
+	prefix := folder + "/" + removeExtension(filename) + " - " + entity + " - "
+	mapTableEntryByTeam := map[string]TableEntry{}
+	for _, row := range readResultsCsv(prefix + "Absolute Stats.csv") {
+		rank, _ := strconv.Atoi(row["Rank"])
+		points, _ := strconv.Atoi(row["Points"])
+		gamesPlayed, _ := strconv.Atoi(row["GamesPlayed"])
+		tableEntry := TableEntry{Rank: rank, Points: points, GamesPlayed: gamesPlayed}
+		if gamesPlayed > 0 {
+			tableEntry.PPG = round(float64(points)/float64(gamesPlayed), 4)
+		}
+		mapTableEntryByTeam[row["Team"]] = tableEntry
+	}
+	if _, err := os.Stat(prefix + "Latest Form.csv"); err == nil {
+		for _, row := range readResultsCsv(prefix + "Latest Form.csv") {
+			if tableEntry, ok := mapTableEntryByTeam[row["Team"]]; ok {
+				tableEntry.Form = row["Form"]
+				mapTableEntryByTeam[row["Team"]] = tableEntry
+			}
+		}
+	}
+	return mapTableEntryByTeam
+}
+
+// Keeps `RawData` records of matches played on or before the given date. Exits if any match doesn't have a date
+func filterRecordsUpToDate(records []RawData, date time.Time) []RawData {
+	recordsFiltered := []RawData{}
+	for _, record := range records {
+		if record.Date.IsZero() {
+			log.Fatalln("Comparing dates needs every match of the data file to have a date")
+		}
+		if !record.Date.After(date) {
+			recordsFiltered = append(recordsFiltered, record)
+		}
+	}
+	return recordsFiltered
+}
+
+/*
+Gets changes in standing of teams between the "before" and "after" tables. Sorted by rank in the "after" table,
+followed by teams that are only in the "before" table.
+*/
+func getTableDiffs(mapBeforeByTeam map[string]TableEntry, mapAfterByTeam map[string]TableEntry) []TableDiff {
+	teams := []string{}
+	for team := range mapAfterByTeam {
+		teams = append(teams, team)
+	}
+	for team := range mapBeforeByTeam {
+		if _, ok := mapAfterByTeam[team]; !ok {
+			teams = append(teams, team)
+		}
+	}
+	sliceTableDiffs := []TableDiff{}
+	for _, team := range teams {
+		before, after := mapBeforeByTeam[team], mapAfterByTeam[team]
+		tempObj := TableDiff{
+			Team:           team,
+			PreviousRank:   before.Rank,
+			Rank:           after.Rank,
+			PreviousPoints: before.Points,
+			Points:         after.Points,
+			PointsDelta:    after.Points - before.Points,
+			PreviousPPG:    before.PPG,
+			PPG:            after.PPG,
+			PPGDelta:       round(after.PPG-before.PPG, 4),
+			PreviousForm:   before.Form,
+			Form:           after.Form,
+		}
+		if before.Rank != 0 && after.Rank != 0 {
+			tempObj.RankMovement = before.Rank - after.Rank
+		}
+		sliceTableDiffs = append(sliceTableDiffs, tempObj)
+	}
+	sort.SliceStable(sliceTableDiffs, func(i, j int) bool {
+		rankOfI, rankOfJ := sliceTableDiffs[i].Rank, sliceTableDiffs[j].Rank
+		if rankOfI == 0 || rankOfJ == 0 {
+			if rankOfI == rankOfJ {
+				return sliceTableDiffs[i].PreviousRank < sliceTableDiffs[j].PreviousRank
+			}
+			return rankOfJ == 0
+		}
+		return rankOfI < rankOfJ
+	})
+	return sliceTableDiffs
+}
+
+// Keeps only those `TableDiff` objects of teams whose rank, points or form changed (or who joined/left the table)
+func removeUnchangedTableDiffs(sliceTableDiffs []TableDiff) []TableDiff {
+	sliceTableDiffsChanged := []TableDiff{}
+	for _, obj := range sliceTableDiffs {
+		if obj.PreviousRank != obj.Rank || obj.PointsDelta != 0 || obj.PreviousForm != obj.Form {
+			sliceTableDiffsChanged = append(sliceTableDiffsChanged, obj)
+		}
+	}
+	return sliceTableDiffsChanged
+}
+
+// Gets a signed representation of a number i.e; "+2", "-1", "0"
+func getSigned(num float64) string {
+	if num > 0 {
+		return "+" + fmt.Sprintf("%g", num)
+	}
+	return fmt.Sprintf("%g", num)
+}
+
+// Prints changes in standing of teams i.e; "Arsenal: 3 -> 1 (+2), +6 pts, PPG +0.12, form LDWWW -> WWWWD"
+func printTableDiffs(sliceTableDiffs []TableDiff) {
+	for _, obj := range sliceTableDiffs {
+		if obj.PreviousRank == 0 {
+			fmt.Println("  " + obj.Team + ": new at " + strconv.Itoa(obj.Rank) + ", " + strconv.Itoa(obj.Points) + " pts, PPG " + fmt.Sprintf("%g", obj.PPG) + ", form " + obj.Form)
+			continue
+		}
+		if obj.Rank == 0 {
+			fmt.Println("  " + obj.Team + ": was " + strconv.Itoa(obj.PreviousRank) + ", no longer in the table")
+			continue
+		}
+		fmt.Println("  " + obj.Team + ": " + strconv.Itoa(obj.PreviousRank) + " -> " + strconv.Itoa(obj.Rank) + " (" + getSigned(float64(obj.RankMovement)) + "), " +
+			getSigned(float64(obj.PointsDelta)) + " pts, PPG " + getSigned(obj.PPGDelta) + ", form " + obj.PreviousForm + " -> " + obj.Form)
+	}
+}
+
+// Saves slice having objects of `TableDiff` struct to CSV file
+func saveTableDiffsToCsv(sliceData []TableDiff, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&TableDiff{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+/*
+Command that compares two computed tables i.e; of a data file in two results folders (-results-before/-results-after),
+of a data file on two dates (-date-before/-date-after), or of two data files such as two seasons
+(-file-before/-file-after). Prints the movers and saves the diff to CSV file.
+*/
+func runDiffCommand(args []string) {
+	flagSet := flag.NewFlagSet("diff", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	filename := flagSet.String("file", "", "Name of the data file (in the data folder) whose tables are compared")
+	resultsBefore := flagSet.String("results-before", "", "Results folder having the \"before\" tables of -file")
+	resultsAfter := flagSet.String("results-after", pathResultsFolder, "Results folder having the \"after\" tables of -file")
+	dateBefore := flagSet.String("date-before", "", "Compare -file as of this date (inclusive) ...")
+	dateAfter := flagSet.String("date-after", "", "... with -file as of this date (inclusive, defaults to all matches)")
+	fileBefore := flagSet.String("file-before", "", "Name of the data file having the \"before\" table i.e; previous season")
+	fileAfter := flagSet.String("file-after", "", "Name of the data file having the \"after\" table i.e; current season")
+	solo := flagSet.Bool("individuals", false, "Compare tables of individuals instead of teams (for 2v2 data files)")
+	output := flagSet.String("output", "", "Path to the CSV file of the diff (defaults to a file in the results folder)")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+
+	var mapBeforeByTeam, mapAfterByTeam map[string]TableEntry
+	var label string
+	if *fileBefore != "" || *fileAfter != "" {
+		if *fileBefore == "" || *fileAfter == "" {
+			log.Fatalln("Comparing data files needs both -file-before and -file-after")
+		}
+		mapBeforeByTeam = getTableEntries(loadRawRecords(pathDataFolder+"/"+*fileBefore, *options), *solo, options.ScoringRules)
+		mapAfterByTeam = getTableEntries(loadRawRecords(pathDataFolder+"/"+*fileAfter, *options), *solo, options.ScoringRules)
+		label = removeExtension(*fileBefore) + " vs " + removeExtension(*fileAfter)
+	} else if *filename == "" {
+		log.Fatalln("Use -file (with -results-before or -date-before), or -file-before and -file-after")
+	} else if *resultsBefore != "" {
+		mapBeforeByTeam = readTableEntriesFromResults(*resultsBefore, *filename, *solo)
+		mapAfterByTeam = readTableEntriesFromResults(*resultsAfter, *filename, *solo)
+		label = removeExtension(*filename)
+	} else if *dateBefore != "" {
+		records := loadRawRecords(pathDataFolder+"/"+*filename, *options)
+		before, err := parseDate(*dateBefore)
+		if err != nil {
+			log.Fatalln("Error while parsing -date-before", err)
+		}
+		recordsAfter := records
+		label = removeExtension(*filename) + " - " + before.Format(dateLayouts[0])
+		if *dateAfter != "" {
+			after, err := parseDate(*dateAfter)
+			if err != nil {
+				log.Fatalln("Error while parsing -date-after", err)
+			}
+			recordsAfter = filterRecordsUpToDate(records, after)
+			label += " vs " + after.Format(dateLayouts[0])
+		}
+		mapBeforeByTeam = getTableEntries(filterRecordsUpToDate(records, before), *solo, options.ScoringRules)
+		mapAfterByTeam = getTableEntries(recordsAfter, *solo, options.ScoringRules)
+	} else {
+		log.Fatalln("Use -results-before or -date-before along with -file")
+	}
+	if *solo {
+		label += " - Individuals"
+	}
+
+	sliceTableDiffs := getTableDiffs(mapBeforeByTeam, mapAfterByTeam)
+	fmt.Println("Movers (" + label + "):")
+	printTableDiffs(sliceTableDiffs)
+	filepath := pathResultsFolder + "/Diff - " + strings.ReplaceAll(label, "/", "-") + ".csv"
+	if *output != "" {
+		filepath = *output
+	}
+	saveTableDiffsToCsv(sliceTableDiffs, filepath)
+	fmt.Println("Saved the diff to '" + filepath + "'")
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestGetTableDiffs(t *testing.T) {
+	mapBeforeByTeam := map[string]TableEntry{
+		"Arsenal": {Rank: 1, Points: 6, GamesPlayed: 2, PPG: 3, Form: "WW"},
+		"Chelsea": {Rank: 2, Points: 3, GamesPlayed: 2, PPG: 1.5, Form: "LW"},
+		"Everton": {Rank: 3, Points: 0, GamesPlayed: 2, PPG: 0, Form: "LL"},
+	}
+	mapAfterByTeam := map[string]TableEntry{
+		"Chelsea": {Rank: 1, Points: 6, GamesPlayed: 3, PPG: 2, Form: "WLW"},
+		"Arsenal": {Rank: 2, Points: 6, GamesPlayed: 3, PPG: 2, Form: "LWW"},
+		"Fulham":  {Rank: 3, Points: 0, GamesPlayed: 0, PPG: 0},
+	}
+	sliceTableDiffs := getTableDiffs(mapBeforeByTeam, mapAfterByTeam)
+	wantTeams := []string{"Chelsea", "Arsenal", "Fulham", "Everton"}
+	if len(sliceTableDiffs) != len(wantTeams) {
+		t.Fatalf("got %d diffs, want %d", len(sliceTableDiffs), len(wantTeams))
+	}
+	for idx, obj := range sliceTableDiffs {
+		if obj.Team != wantTeams[idx] {
+			t.Errorf("diff %d: got %s, want %s", idx, obj.Team, wantTeams[idx])
+		}
+	}
+	if chelsea := sliceTableDiffs[0]; chelsea.RankMovement != 1 || chelsea.PointsDelta != 3 || chelsea.PPGDelta != 0.5 {
+		t.Errorf("Chelsea: got %+v", chelsea)
+	}
+	if fulham := sliceTableDiffs[2]; fulham.PreviousRank != 0 || fulham.RankMovement != 0 {
+		t.Errorf("Fulham (new to the table): got %+v", fulham)
+	}
+	if everton := sliceTableDiffs[3]; everton.Rank != 0 || everton.RankMovement != 0 {
+		t.Errorf("Everton (no longer in the table): got %+v", everton)
+	}
+}
+
+func TestRemoveUnchangedTableDiffs(t *testing.T) {
+	mapBeforeByTeam := map[string]TableEntry{
+		"Arsenal": {Rank: 1, Points: 6, GamesPlayed: 2, PPG: 3, Form: "WW"},
+		"Chelsea": {Rank: 2, Points: 3, GamesPlayed: 2, PPG: 1.5, Form: "LW"},
+		"Everton": {Rank: 3, Points: 0, GamesPlayed: 2, PPG: 0, Form: "LL"},
+	}
+	mapAfterByTeam := map[string]TableEntry{
+		"Arsenal": {Rank: 1, Points: 9, GamesPlayed: 3, PPG: 3, Form: "WWW"},
+		"Chelsea": {Rank: 2, Points: 3, GamesPlayed: 2, PPG: 1.5, Form: "LW"},
+		"Everton": {Rank: 3, Points: 0, GamesPlayed: 3, PPG: 0, Form: "LLL"},
+	}
+	sliceTableDiffs := removeUnchangedTableDiffs(getTableDiffs(mapBeforeByTeam, mapAfterByTeam))
+	if len(sliceTableDiffs) != 2 || sliceTableDiffs[0].Team != "Arsenal" || sliceTableDiffs[1].Team != "Everton" {
+		t.Errorf("got %+v, want diffs of Arsenal and Everton", sliceTableDiffs)
+	}
+	if len(removeUnchangedTableDiffs(getTableDiffs(mapAfterByTeam, mapAfterByTeam))) != 0 {
+		t.Error("diffs of a table with itself weren't all removed")
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Minimum expected number of matches per bin of the chi-square test. Bins with fewer are merged with their neighbours
+const chiSquareMinExpected = 5.0
+
+// Name used in place of a team for league-wide distributions
+const leagueWide = "League"
+
+/*
+Struct to store a summary of the goal distribution of a team's matches (or of all matches, if `Team` is "League").
+`ChiSquare` tests how well a Poisson distribution (having the observed mean) fits the number of goals per match.
+*/
+type GoalDistribution struct {
+	Team                string
+	GamesPlayed         int
+	GoalsPerMatch       float64 // Goals by both sides
+	Over25Pct           float64 // Matches having 3 or more goals
+	Under25Pct          float64
+	BttsPct             float64 // Matches wherein both teams scored
+	MostCommonScoreline string
+	ChiSquare           float64
+	DegreesOfFreedom    int
+	PValue              float64 // NaN if there are too few matches for the test
+}
+
+/*
+Struct to store frequency of a scoreline. League-wide scorelines are home-away, while scorelines of a team are
+goals for-against.
+*/
+type ScorelineFrequency struct {
+	Team       string
+	Scoreline  string
+	Matches    int
+	Pct        float64
+	PoissonPct float64 // As per independent Poisson distributions of goals for and against, having the observed means
+}
+
+// Struct to store frequency of a number of goals per match (by both sides). The last bin is "N+"
+type GoalsPerMatchFrequency struct {
+	Team       string
+	Goals      string
+	Matches    int
+	Pct        float64
+	PoissonPct float64 // As per a Poisson distribution having the observed mean
+}
+
+/*
+Method that gets slice of stringified elements of `GoalDistribution` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `GoalDistribution` struct to CSV file.
+*/
+func (obj GoalDistribution) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, fmt.Sprintf("%g", obj.GoalsPerMatch))
+	values = append(values, fmt.Sprintf("%g", obj.Over25Pct))
+	values = append(values, fmt.Sprintf("%g", obj.Under25Pct))
+	values = append(values, fmt.Sprintf("%g", obj.BttsPct))
+	values = append(values, obj.MostCommonScoreline)
+	values = append(values, fmt.Sprintf("%g", obj.ChiSquare))
+	values = append(values, strconv.Itoa(obj.DegreesOfFreedom))
+	values = append(values, fmt.Sprintf("%g", obj.PValue))
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `ScorelineFrequency` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `ScorelineFrequency` struct to CSV file.
+*/
+func (obj ScorelineFrequency) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Team)
+	values = append(values, obj.Scoreline)
+	values = append(values, strconv.Itoa(obj.Matches))
+	values = append(values, fmt.Sprintf("%g", obj.Pct))
+	values = append(values, fmt.Sprintf("%g", obj.PoissonPct))
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `GoalsPerMatchFrequency` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `GoalsPerMatchFrequency` struct to CSV file.
+*/
+func (obj GoalsPerMatchFrequency) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Team)
+	values = append(values, obj.Goals)
+	values = append(values, strconv.Itoa(obj.Matches))
+	values = append(values, fmt.Sprintf("%g", obj.Pct))
+	values = append(values, fmt.Sprintf("%g", obj.PoissonPct))
+	return values
+}
+
+/*
+Gets goals for and against in each match of a team from `RawData` records (going by the score used in tables). If the
+team is "League", gets home and away goals of all matches instead.
+*/
+func getGoalPairs(records []RawData, team string, rules ScoringRules) [][2]int {
+	goalPairs := [][2]int{}
+	for _, record := range records {
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		if team == leagueWide || record.HomeTeam == team {
+			goalPairs = append(goalPairs, [2]int{homeGoals, awayGoals})
+		} else if record.AwayTeam == team {
+			goalPairs = append(goalPairs, [2]int{awayGoals, homeGoals})
+		}
+	}
+	return goalPairs
+}
+
+// Gets mean goals for and mean goals against from goal pairs (see `getGoalPairs`)
+func getMeanGoals(goalPairs [][2]int) (float64, float64) {
+	goalsFor, goalsAgainst := 0, 0
+	for _, goalPair := range goalPairs {
+		goalsFor += goalPair[0]
+		goalsAgainst += goalPair[1]
+	}
+	return float64(goalsFor) / float64(len(goalPairs)), float64(goalsAgainst) / float64(len(goalPairs))
+}
+
+/*
+Gets the regularized upper incomplete gamma function Q(a, x), by its series expansion (for x < a + 1) or else its
+continued fraction. The p-value of a chi-square statistic X having k degrees of freedom is Q(k / 2, X / 2).
+*/
+func getRegularizedGammaQ(a float64, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lgammaA, _ := math.Lgamma(a)
+	logPrefactor := a*math.Log(x) - x - lgammaA
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n < 1000 && math.Abs(term) > math.Abs(sum)*1e-15; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+		}
+		return 1 - sum*math.Exp(logPrefactor)
+	}
+	tiny := 1e-300
+	b := x + 1 - a
+	c, d := 1/tiny, 1/b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(logPrefactor) * h
+}
+
+/*
+Gets chi-square goodness-of-fit statistic of a Poisson distribution (having the observed mean) to goals per match,
+along with its degrees of freedom and p-value. Bins of goals are merged until each expects at least
+`chiSquareMinExpected` matches, and the last bin covers all higher goals. One degree of freedom is lost to the
+fitted mean. The p-value is NaN if fewer than 3 bins are left.
+*/
+func getPoissonChiSquare(goalsPerMatch []int) (float64, int, float64) {
+	numMatches := float64(len(goalsPerMatch))
+	mapMatchesByGoals, total := map[int]int{}, 0
+	for _, goals := range goalsPerMatch {
+		mapMatchesByGoals[goals]++
+		total += goals
+	}
+	lambda := float64(total) / numMatches
+	observedBins, expectedBins := []float64{}, []float64{}
+	observed, expected, cumulativeProbability, numMatchesBinned := 0.0, 0.0, 0.0, 0
+	for goals := 0; ; goals++ {
+		probability := getPoissonProbability(goals, lambda)
+		observed += float64(mapMatchesByGoals[goals])
+		expected += numMatches * probability
+		cumulativeProbability += probability
+		numMatchesBinned += mapMatchesByGoals[goals]
+		expectedTail := numMatches * (1 - cumulativeProbability)
+		if expectedTail < chiSquareMinExpected {
+			observed += float64(len(goalsPerMatch) - numMatchesBinned)
+			expected += math.Max(expectedTail, 0)
+			if expected < chiSquareMinExpected && len(observedBins) > 0 {
+				observedBins[len(observedBins)-1] += observed
+				expectedBins[len(expectedBins)-1] += expected
+			} else {
+				observedBins, expectedBins = append(observedBins, observed), append(expectedBins, expected)
+			}
+			break
+		}
+		if expected >= chiSquareMinExpected {
+			observedBins, expectedBins = append(observedBins, observed), append(expectedBins, expected)
+			observed, expected = 0, 0
+		}
+	}
+	chiSquare := 0.0
+	for idx := range observedBins {
+		chiSquare += math.Pow(observedBins[idx]-expectedBins[idx], 2) / expectedBins[idx]
+	}
+	degreesOfFreedom := len(observedBins) - 2
+	if degreesOfFreedom < 1 {
+		return round(chiSquare, 3), 0, math.NaN()
+	}
+	pValue := getRegularizedGammaQ(float64(degreesOfFreedom)/2, chiSquare/2)
+	return round(chiSquare, 3), degreesOfFreedom, round(pValue, 4)
+}
+
+// Gets frequencies of scorelines from goal pairs (see `getGoalPairs`), sorted by most frequent first
+func getScorelineFrequencies(goalPairs [][2]int, team string) []ScorelineFrequency {
+	lambdaFor, lambdaAgainst := getMeanGoals(goalPairs)
+	mapMatchesByScoreline, scorelines := map[[2]int]int{}, [][2]int{}
+	for _, goalPair := range goalPairs {
+		if mapMatchesByScoreline[goalPair] == 0 {
+			scorelines = append(scorelines, goalPair)
+		}
+		mapMatchesByScoreline[goalPair]++
+	}
+	sort.Slice(scorelines, func(i, j int) bool {
+		if mapMatchesByScoreline[scorelines[i]] != mapMatchesByScoreline[scorelines[j]] {
+			return mapMatchesByScoreline[scorelines[i]] > mapMatchesByScoreline[scorelines[j]]
+		}
+		if scorelines[i][0] != scorelines[j][0] {
+			return scorelines[i][0] < scorelines[j][0]
+		}
+		return scorelines[i][1] < scorelines[j][1]
+	})
+	sliceScorelineFrequencies := []ScorelineFrequency{}
+	for _, scoreline := range scorelines {
+		poissonProbability := getPoissonProbability(scoreline[0], lambdaFor) * getPoissonProbability(scoreline[1], lambdaAgainst)
+		tempObj := ScorelineFrequency{
+			Team:       team,
+			Scoreline:  strconv.Itoa(scoreline[0]) + "-" + strconv.Itoa(scoreline[1]),
+			Matches:    mapMatchesByScoreline[scoreline],
+			Pct:        round(float64(mapMatchesByScoreline[scoreline])*100/float64(len(goalPairs)), 2),
+			PoissonPct: round(poissonProbability*100, 2),
+		}
+		sliceScorelineFrequencies = append(sliceScorelineFrequencies, tempObj)
+	}
+	return sliceScorelineFrequencies
+}
+
+// Gets histogram of goals per match (by both sides) from goal pairs (see `getGoalPairs`), up to the most goals seen
+func getGoalsPerMatchFrequencies(goalPairs [][2]int, team string) []GoalsPerMatchFrequency {
+	lambdaFor, lambdaAgainst := getMeanGoals(goalPairs)
+	mapMatchesByGoals, maxGoals := map[int]int{}, 0
+	for _, goalPair := range goalPairs {
+		goals := goalPair[0] + goalPair[1]
+		mapMatchesByGoals[goals]++
+		if goals > maxGoals {
+			maxGoals = goals
+		}
+	}
+	sliceGoalsPerMatchFrequencies := []GoalsPerMatchFrequency{}
+	cumulativeProbability := 0.0
+	for goals := 0; goals <= maxGoals; goals++ {
+		label, probability := strconv.Itoa(goals), getPoissonProbability(goals, lambdaFor+lambdaAgainst)
+		if goals == maxGoals {
+			label, probability = label+"+", 1-cumulativeProbability
+		}
+		cumulativeProbability += probability
+		tempObj := GoalsPerMatchFrequency{
+			Team:       team,
+			Goals:      label,
+			Matches:    mapMatchesByGoals[goals],
+			Pct:        round(float64(mapMatchesByGoals[goals])*100/float64(len(goalPairs)), 2),
+			PoissonPct: round(probability*100, 2),
+		}
+		sliceGoalsPerMatchFrequencies = append(sliceGoalsPerMatchFrequencies, tempObj)
+	}
+	return sliceGoalsPerMatchFrequencies
+}
+
+// Gets summary of the goal distribution from goal pairs (see `getGoalPairs`)
+func getGoalDistribution(goalPairs [][2]int, team string) GoalDistribution {
+	numOver, numBtts, goalsPerMatch := 0, 0, []int{}
+	for _, goalPair := range goalPairs {
+		goals := goalPair[0] + goalPair[1]
+		goalsPerMatch = append(goalsPerMatch, goals)
+		if goals > 2 {
+			numOver++
+		}
+		if goalPair[0] > 0 && goalPair[1] > 0 {
+			numBtts++
+		}
+	}
+	lambdaFor, lambdaAgainst := getMeanGoals(goalPairs)
+	chiSquare, degreesOfFreedom, pValue := getPoissonChiSquare(goalsPerMatch)
+	numMatches := float64(len(goalPairs))
+	return GoalDistribution{
+		Team:                team,
+		GamesPlayed:         len(goalPairs),
+		GoalsPerMatch:       round(lambdaFor+lambdaAgainst, 3),
+		Over25Pct:           round(float64(numOver)*100/numMatches, 2),
+		Under25Pct:          round(float64(len(goalPairs)-numOver)*100/numMatches, 2),
+		BttsPct:             round(float64(numBtts)*100/numMatches, 2),
+		MostCommonScoreline: getScorelineFrequencies(goalPairs, team)[0].Scoreline,
+		ChiSquare:           chiSquare,
+		DegreesOfFreedom:    degreesOfFreedom,
+		PValue:              pValue,
+	}
+}
+
+/*
+Gets goal distributions of all matches ("League") followed by every team, from `RawData` records i.e; summaries,
+scoreline frequencies and goals per match histograms. Teams are sorted by goals per match (highest first).
+*/
+func getGoalDistributions(records []RawData, rules ScoringRules) ([]GoalDistribution, []ScorelineFrequency, []GoalsPerMatchFrequency) {
+	sliceGoalDistributions := []GoalDistribution{}
+	sliceScorelineFrequencies := []ScorelineFrequency{}
+	sliceGoalsPerMatchFrequencies := []GoalsPerMatchFrequency{}
+	if len(records) == 0 {
+		return sliceGoalDistributions, sliceScorelineFrequencies, sliceGoalsPerMatchFrequencies
+	}
+	sliceTeamDistributions := []GoalDistribution{}
+	for _, team := range getUniqueTeamNames(records) {
+		sliceTeamDistributions = append(sliceTeamDistributions, getGoalDistribution(getGoalPairs(records, team, rules), team))
+	}
+	sort.SliceStable(sliceTeamDistributions, func(i, j int) bool {
+		return sliceTeamDistributions[i].GoalsPerMatch > sliceTeamDistributions[j].GoalsPerMatch
+	})
+	sliceGoalDistributions = append(sliceGoalDistributions, getGoalDistribution(getGoalPairs(records, leagueWide, rules), leagueWide))
+	sliceGoalDistributions = append(sliceGoalDistributions, sliceTeamDistributions...)
+	for _, obj := range sliceGoalDistributions {
+		goalPairs := getGoalPairs(records, obj.Team, rules)
+		sliceScorelineFrequencies = append(sliceScorelineFrequencies, getScorelineFrequencies(goalPairs, obj.Team)...)
+		sliceGoalsPerMatchFrequencies = append(sliceGoalsPerMatchFrequencies, getGoalsPerMatchFrequencies(goalPairs, obj.Team)...)
+	}
+	return sliceGoalDistributions, sliceScorelineFrequencies, sliceGoalsPerMatchFrequencies
+}
+
+// Saves slice having objects of `GoalDistribution` struct to CSV file
+func saveGoalDistributionsToCsv(sliceData []GoalDistribution, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&GoalDistribution{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `ScorelineFrequency` struct to CSV file
+func saveScorelineFrequenciesToCsv(sliceData []ScorelineFrequency, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&ScorelineFrequency{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `GoalsPerMatchFrequency` struct to CSV file
+func saveGoalsPerMatchFrequenciesToCsv(sliceData []GoalsPerMatchFrequency, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&GoalsPerMatchFrequency{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Gets p-value of a chi-square statistic `x`, in closed form for 1, 2 and 4 degrees of freedom
+func getChiSquarePValueInClosedForm(x float64, degreesOfFreedom int) float64 {
+	switch degreesOfFreedom {
+	case 1:
+		return math.Erfc(math.Sqrt(x / 2))
+	case 2:
+		return math.Exp(-x / 2)
+	case 4:
+		return math.Exp(-x/2) * (1 + x/2)
+	}
+	return math.NaN()
+}
+
+func TestGetRegularizedGammaQ(t *testing.T) {
+	// Statistics on both sides of a + 1, so both the series expansion and the continued fraction are covered
+	for _, degreesOfFreedom := range []int{1, 2, 4} {
+		for _, x := range []float64{0.01, 0.5, 1, 2.5, 3.841, 6, 10, 25} {
+			got := getRegularizedGammaQ(float64(degreesOfFreedom)/2, x/2)
+			if want := getChiSquarePValueInClosedForm(x, degreesOfFreedom); math.Abs(got-want) > 1e-9 {
+				t.Errorf("X = %g, %d degree/s of freedom: got %g, want %g", x, degreesOfFreedom, got, want)
+			}
+		}
+	}
+	if got := getRegularizedGammaQ(3, 0); got != 1 {
+		t.Errorf("x = 0: got %g, want 1", got)
+	}
+}
+
+func TestGetPoissonChiSquare(t *testing.T) {
+	// 100 matches having 0 to 4 goals, with a mean of 1.65 goals
+	goalsPerMatch := []int{}
+	for goals, numMatches := range []int{20, 30, 25, 15, 10} {
+		for idx := 0; idx < numMatches; idx++ {
+			goalsPerMatch = append(goalsPerMatch, goals)
+		}
+	}
+	chiSquare, degreesOfFreedom, pValue := getPoissonChiSquare(goalsPerMatch)
+	if chiSquare != 0.433 || degreesOfFreedom != 3 || pValue != 0.9334 {
+		t.Errorf("got X = %g, %d degree/s of freedom, p = %g; want X = 0.433, 3 degrees of freedom, p = 0.9334",
+			chiSquare, degreesOfFreedom, pValue)
+	}
+	// Few matches leave fewer than 3 bins, so there's no p-value
+	chiSquare, degreesOfFreedom, pValue = getPoissonChiSquare([]int{0, 1, 1, 2, 3, 1})
+	if degreesOfFreedom != 0 || !math.IsNaN(pValue) {
+		t.Errorf("6 matches: got X = %g, %d degree/s of freedom, p = %g; want no degrees of freedom and a NaN p-value",
+			chiSquare, degreesOfFreedom, pValue)
+	}
+}
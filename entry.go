@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants - Locking of data files while adding matches
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+)
+
+/*
+Validates a new match before adding it to the data file `filename`, with the same rules as data files i.e;
+home and away teams must differ, and 2v2 data files must follow the 2v2 naming convention (without any individual
+playing for both teams). Returns nil if the match is valid.
+*/
+func validateNewMatch(record RawData, filename string, options PipelineOptions) error {
+	if record.HomeTeam == "" || record.AwayTeam == "" {
+		return errors.New("home team and away team are required")
+	}
+	if record.HomeGoals < 0 || record.AwayGoals < 0 {
+		return errors.New("goals can't be negative")
+	}
+	if record.HomeGoals > options.MaxGoals || record.AwayGoals > options.MaxGoals {
+		return errors.New("goals by one side can't be more than " + strconv.Itoa(options.MaxGoals) + " (see -max-goals)")
+	}
+	if isHomeSameAsAway([]RawData{record}) {
+		return errors.New("home team '" + record.HomeTeam + "' is same as away team")
+	}
+	if filenameContains2v2(filename) {
+		if !isValid2v2Naming([]RawData{record}) {
+			return errors.New("team-names of 2v2 games must be unique-names of both individuals i.e; 'AnkurNishant'")
+		}
+		for _, individual := range regexp.MustCompile(`[A-Z][^A-Z]*`).FindAllString(record.HomeTeam, -1) {
+			if individualInTeam(individual, record.AwayTeam) {
+				return errors.New("'" + individual + "' can't play for both teams")
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Acquires the lock of a data file i.e; creates "<filepath>.lock" exclusively, retrying until `lockTimeout`.
+Returns a function that releases the lock. A lock left behind by a crashed process must be deleted by hand.
+*/
+func lockDataFile(filepath string) (func(), error) {
+	pathLock := filepath + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lockfile, err := os.OpenFile(pathLock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+		if err == nil {
+			fmt.Fprintln(lockfile, os.Getpid())
+			lockfile.Close()
+			return func() { os.Remove(pathLock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("'" + filepath + "' is locked by another process (delete '" + pathLock + "' if it's stale)")
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+/*
+Writes a new match to a data file, while holding its lock. If the data file has a fixture yet to be played between
+the same home and away teams, the earliest such fixture is filled in. Otherwise the match is appended.
+The date is written only if the data file has a "Date" column (today's date if the match doesn't have one).
+The data file is rewritten through a temporary file, so it's never left half-written.
+*/
+func writeMatchToDataFile(filepath string, record RawData) error {
+	unlock, err := lockDataFile(filepath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	csvfile, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	r := csv.NewReader(csvfile)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	csvfile.Close()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 || len(rows[0]) < 4 {
+		return errors.New("'" + filepath + "' doesn't have a header having the columns HomeTeam, HomeGoals, AwayGoals, AwayTeam")
+	}
+	mapColumnIndexByName := getOptionalColumnIndexes(rows[0])
+	date := record.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	idxFixture := -1
+	for idx := 1; idx < len(rows); idx++ {
+		row := rows[idx]
+		if len(row) >= 4 && row[1] == "" && row[2] == "" && row[0] == record.HomeTeam && row[3] == record.AwayTeam {
+			idxFixture = idx
+			break
+		}
+	}
+	if idxFixture == -1 {
+		rows = append(rows, make([]string, len(rows[0])))
+		idxFixture = len(rows) - 1
+	}
+	row := rows[idxFixture]
+	for len(row) < len(rows[0]) {
+		row = append(row, "") // Fixture rows may be shorter than the header i.e; without trailing optional columns
+	}
+	rows[idxFixture] = row
+	row[0], row[1], row[2], row[3] = record.HomeTeam, strconv.Itoa(record.HomeGoals), strconv.Itoa(record.AwayGoals), record.AwayTeam
+	if idx, ok := mapColumnIndexByName["date"]; ok && (row[idx] == "" || !record.Date.IsZero()) {
+		row[idx] = date.Format(dateLayouts[0])
+	}
+
+	pathTemp := filepath + ".tmp"
+	tempfile, err := os.OpenFile(pathTemp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(tempfile)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		tempfile.Close()
+		os.Remove(pathTemp)
+		return err
+	}
+	if err := tempfile.Close(); err != nil {
+		os.Remove(pathTemp)
+		return err
+	}
+	return os.Rename(pathTemp, filepath)
+}
+
+/*
+Adds a new match to the data file `filename` i.e; validates it, writes it to the data file, and recomputes the
+tables of the data file (and all-time tables, which span data files). Returns an error if the match is invalid or
+couldn't be written. Returns false if the data file then fails validation.
+*/
+func addMatch(filename string, record RawData, options PipelineOptions, store *ResultStore) (bool, error) {
+	filepath := pathDataFolder + "/" + filename
+	if _, err := os.Stat(filepath); err != nil {
+		return false, errors.New("unknown data file '" + filename + "'")
+	}
+	if err := validateNewMatch(record, filename, options); err != nil {
+		return false, err
+	}
+	if err := writeMatchToDataFile(filepath, record); err != nil {
+		return false, err
+	}
+	fmt.Println("Added " + record.HomeTeam + " " + strconv.Itoa(record.HomeGoals) + "-" + strconv.Itoa(record.AwayGoals) + " " + record.AwayTeam + " to '" + filename + "'")
+	if !executePipeline(filename, options, store) {
+		return false, nil
+	}
+	executeAggregationPipeline(getListOfDataFilenames(), options)
+	return true, nil
+}
+
+// Parses a score in the format "HomeGoals-AwayGoals" i.e; "3-1"
+func parseScore(score string) (int, int, error) {
+	goals := strings.Split(strings.TrimSpace(score), "-")
+	if len(goals) != 2 {
+		return 0, 0, errors.New("score '" + score + "' must be in the format 'HomeGoals-AwayGoals'")
+	}
+	homeGoals, strConvErrHome := strconv.Atoi(strings.TrimSpace(goals[0]))
+	awayGoals, strConvErrAway := strconv.Atoi(strings.TrimSpace(goals[1]))
+	if strConvErrHome != nil || strConvErrAway != nil {
+		return 0, 0, errors.New("score '" + score + "' must be in the format 'HomeGoals-AwayGoals'")
+	}
+	return homeGoals, awayGoals, nil
+}
+
+// Command that adds a played match to a data file, and recomputes its tables
+func runAddCommand(args []string) {
+	flagSet := flag.NewFlagSet("add", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	filename := flagSet.String("file", "", "Name of the data file (in the data folder) to add the match to")
+	homeTeam := flagSet.String("home", "", "Home team-name")
+	awayTeam := flagSet.String("away", "", "Away team-name")
+	score := flagSet.String("score", "", "Score in the format 'HomeGoals-AwayGoals' i.e; '3-1'")
+	date := flagSet.String("date", "", "Date of the match (defaults to today, if the data file has dates)")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+
+	record := RawData{HomeTeam: *homeTeam, AwayTeam: *awayTeam}
+	var err error
+	if record.HomeGoals, record.AwayGoals, err = parseScore(*score); err != nil {
+		log.Fatalln("Invalid -score:", err)
+	}
+	if *date != "" {
+		if record.Date, err = parseDate(*date); err != nil {
+			log.Fatalln("Error while parsing -date", err)
+		}
+	}
+	var store *ResultStore
+	if options.DatabasePath != "" {
+		store = openResultStore(options.DatabasePath, *options)
+	}
+	isValid, err := addMatch(*filename, record, *options, store)
+	if store != nil {
+		store.close()
+	}
+	if err != nil {
+		log.Fatalln("Couldn't add the match:", err)
+	}
+	if !isValid {
+		os.Exit(1)
+	}
+}
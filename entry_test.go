@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriteMatchToDataFileFillsShortFixtureRow(t *testing.T) {
+	filepath := t.TempDir() + "/League.csv"
+	content := "HomeTeam,HomeGoals,AwayGoals,AwayTeam,Date\n" +
+		"Arsenal,2,1,Chelsea,2024-08-10\n" +
+		"Chelsea,,,Arsenal\n" // Fixture row without the trailing Date column
+	if err := os.WriteFile(filepath, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 8, 17, 0, 0, 0, 0, time.UTC)
+	if err := writeMatchToDataFile(filepath, RawData{HomeTeam: "Chelsea", HomeGoals: 0, AwayGoals: 0, AwayTeam: "Arsenal", Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMatchToDataFile(filepath, RawData{HomeTeam: "Arsenal", HomeGoals: 3, AwayGoals: 3, AwayTeam: "Chelsea", Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HomeTeam,HomeGoals,AwayGoals,AwayTeam,Date\n" +
+		"Arsenal,2,1,Chelsea,2024-08-10\n" +
+		"Chelsea,0,0,Arsenal," + date.Format(dateLayouts[0]) + "\n" +
+		"Arsenal,3,3,Chelsea," + date.Format(dateLayouts[0]) + "\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteMatchToDataFileRejectsMissingHeader(t *testing.T) {
+	filepath := t.TempDir() + "/League.csv"
+	if err := os.WriteFile(filepath, []byte("HomeTeam,HomeGoals\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMatchToDataFile(filepath, RawData{HomeTeam: "Arsenal", HomeGoals: 1, AwayGoals: 0, AwayTeam: "Chelsea"}); err == nil {
+		t.Error("match was written to a data file whose header doesn't have the required columns")
+	}
+}
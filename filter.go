@@ -0,0 +1,273 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Struct to store a filter of matches, parsed from a clause of a filter expression (see `parseMatchFilters`).
+A filter either keeps a subset of matches league-wide (`Keep`), or is a perspective filter deciding whether a match
+counts for its home/away side (`KeepSide`) i.e; "home games only" keeps a match for the home team, but not for the
+away team. Perspective filters are built from the matches left by league-wide filters.
+*/
+type MatchFilter struct {
+	Clause   string
+	Keep     func(records []RawData) []RawData
+	KeepSide func(records []RawData) func(record RawData, isHome bool) bool
+}
+
+// Keeps `RawData` records of matches played between `from` and `to` (inclusive, ignored if zero). Undated matches are left out
+func filterByDateRange(records []RawData, from time.Time, to time.Time) []RawData {
+	recordsFiltered := []RawData{}
+	for _, record := range records {
+		if record.Date.IsZero() || (!from.IsZero() && record.Date.Before(from)) || (!to.IsZero() && record.Date.After(to)) {
+			continue
+		}
+		recordsFiltered = append(recordsFiltered, record)
+	}
+	return recordsFiltered
+}
+
+/*
+Splits `RawData` records into matchdays, wherein no team plays twice i.e; a new matchday starts as soon as a team
+plays again. NOTE: Assumes that the records are sorted in ascending order of time of occurence of matches.
+*/
+func getMatchdays(records []RawData) [][]RawData {
+	matchdays := [][]RawData{}
+	matchday, isPlaying := []RawData{}, map[string]bool{}
+	for _, record := range records {
+		if isPlaying[record.HomeTeam] || isPlaying[record.AwayTeam] {
+			matchdays = append(matchdays, matchday)
+			matchday, isPlaying = []RawData{}, map[string]bool{}
+		}
+		matchday = append(matchday, record)
+		isPlaying[record.HomeTeam], isPlaying[record.AwayTeam] = true, true
+	}
+	if len(matchday) > 0 {
+		matchdays = append(matchdays, matchday)
+	}
+	return matchdays
+}
+
+// Keeps `RawData` records of the last `numMatchdays` matchdays (see `getMatchdays`)
+func filterByLastMatchdays(records []RawData, numMatchdays int) []RawData {
+	matchdays := getMatchdays(records)
+	if len(matchdays) > numMatchdays {
+		matchdays = matchdays[len(matchdays)-numMatchdays:]
+	}
+	recordsFiltered := []RawData{}
+	for _, matchday := range matchdays {
+		recordsFiltered = append(recordsFiltered, matchday...)
+	}
+	return recordsFiltered
+}
+
+// Keeps `RawData` records of matches involving any of the teams (or individuals, if `solo` is true)
+func filterByTeams(records []RawData, teams []string, solo bool) []RawData {
+	recordsFiltered := []RawData{}
+	for _, record := range records {
+		for _, team := range teams {
+			if teamInMatch(record, team, solo) {
+				recordsFiltered = append(recordsFiltered, record)
+				break
+			}
+		}
+	}
+	return recordsFiltered
+}
+
+// Gets set of teams in the top half (or bottom half, if `topHalf` is false) of the table of `RawData` records
+func getTeamsInHalf(records []RawData, topHalf bool, rules ScoringRules) map[string]bool {
+	sliceAbsStats := sortAbsStatsByMetric(getAbsoluteStats(records, rules))
+	isInHalf := map[string]bool{}
+	for idx, obj := range sliceAbsStats {
+		if (idx < len(sliceAbsStats)/2) == topHalf {
+			isInHalf[obj.Team] = true
+		}
+	}
+	return isInHalf
+}
+
+/*
+Parses a filter expression into filters of matches, which are applied in order. An expression has clauses separated
+by ";", each being one of:
+"from=DATE" and "to=DATE" (matches played within the dates, inclusive), "last-matchdays=N",
+"teams=A,B" and "individuals=A,B" (matches involving any of them), "venue=home" or "venue=away" (perspective),
+"opponents=top-half" or "opponents=bottom-half" (perspective, as per the table of matches left by earlier filters).
+Eg: "from=2012-01-01; venue=home; opponents=top-half". Tables are ranked as per the given scoring rules.
+*/
+func parseMatchFilters(expression string, rules ScoringRules) ([]MatchFilter, error) {
+	filters := []MatchFilter{}
+	for _, clause := range strings.Split(expression, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		keyValue := strings.SplitN(clause, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, errors.New("filter '" + clause + "' must be in the format 'key=value'")
+		}
+		key, value := strings.ToLower(strings.TrimSpace(keyValue[0])), strings.TrimSpace(keyValue[1])
+		filter := MatchFilter{Clause: clause}
+		switch key {
+		case "from", "to":
+			date, err := parseDate(value)
+			if err != nil {
+				return nil, errors.New("invalid date in filter '" + clause + "'")
+			}
+			from, to := date, time.Time{}
+			if key == "to" {
+				from, to = time.Time{}, date
+			}
+			filter.Keep = func(records []RawData) []RawData { return filterByDateRange(records, from, to) }
+		case "last-matchdays":
+			numMatchdays, err := strconv.Atoi(value)
+			if err != nil || numMatchdays < 1 {
+				return nil, errors.New("invalid number of matchdays in filter '" + clause + "'")
+			}
+			filter.Keep = func(records []RawData) []RawData { return filterByLastMatchdays(records, numMatchdays) }
+		case "teams", "individuals":
+			teams := splitNames(value)
+			if len(teams) == 0 {
+				return nil, errors.New("filter '" + clause + "' needs at least one name")
+			}
+			solo := key == "individuals"
+			filter.Keep = func(records []RawData) []RawData { return filterByTeams(records, teams, solo) }
+		case "venue":
+			if value != "home" && value != "away" {
+				return nil, errors.New("venue in filter '" + clause + "' must be 'home' or 'away'")
+			}
+			isHomeKept := value == "home"
+			filter.KeepSide = func(records []RawData) func(record RawData, isHome bool) bool {
+				return func(record RawData, isHome bool) bool { return isHome == isHomeKept }
+			}
+		case "opponents":
+			if value != "top-half" && value != "bottom-half" {
+				return nil, errors.New("opponents in filter '" + clause + "' must be 'top-half' or 'bottom-half'")
+			}
+			topHalf := value == "top-half"
+			filter.KeepSide = func(records []RawData) func(record RawData, isHome bool) bool {
+				isInHalf := getTeamsInHalf(records, topHalf, rules)
+				return func(record RawData, isHome bool) bool {
+					if isHome {
+						return isInHalf[record.AwayTeam]
+					}
+					return isInHalf[record.HomeTeam]
+				}
+			}
+		default:
+			return nil, errors.New("unknown filter '" + key + "'")
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+/*
+Applies filters to `RawData` records, in order. Returns the matches left by league-wide filters, along with the
+combined perspective filters (nil if there are none), which decide whether a match counts for its home/away side
+*/
+func applyMatchFilters(records []RawData, filters []MatchFilter) ([]RawData, func(record RawData, isHome bool) bool) {
+	keepSideFuncs := []func(record RawData, isHome bool) bool{}
+	for _, filter := range filters {
+		if filter.Keep != nil {
+			records = filter.Keep(records)
+		} else {
+			keepSideFuncs = append(keepSideFuncs, filter.KeepSide(records))
+		}
+	}
+	if len(keepSideFuncs) == 0 {
+		return records, nil
+	}
+	return records, func(record RawData, isHome bool) bool {
+		for _, keepSide := range keepSideFuncs {
+			if !keepSide(record, isHome) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Keeps `RawData` records of matches that count for the team (or individual, if `solo` is true) as per `keepSide`
+func getRecordsForTeam(records []RawData, team string, solo bool, keepSide func(record RawData, isHome bool) bool) []RawData {
+	recordsFiltered := []RawData{}
+	for _, record := range records {
+		isHome, isAway := record.HomeTeam == team, record.AwayTeam == team
+		if solo {
+			isHome, isAway = individualInTeam(team, record.HomeTeam), individualInTeam(team, record.AwayTeam)
+		}
+		if (isHome && keepSide(record, true)) || (!isHome && isAway && keepSide(record, false)) {
+			recordsFiltered = append(recordsFiltered, record)
+		}
+	}
+	return recordsFiltered
+}
+
+/*
+Gets slice of absolute stats of teams from `RawData` records as per filters i.e; stats of each team are computed by
+`getAbsoluteStats` from the matches that count for the team. Teams without any such matches are left out.
+*/
+func getAbsoluteStatsFiltered(records []RawData, filters []MatchFilter, rules ScoringRules) []StatsAbs {
+	records, keepSide := applyMatchFilters(records, filters)
+	if keepSide == nil {
+		return getAbsoluteStats(records, rules)
+	}
+	sliceAbsoluteStats := []StatsAbs{}
+	for _, team := range getUniqueTeamNames(records) {
+		for _, obj := range getAbsoluteStats(getRecordsForTeam(records, team, false, keepSide), rules) {
+			if obj.Team == team {
+				sliceAbsoluteStats = append(sliceAbsoluteStats, obj)
+			}
+		}
+	}
+	return sliceAbsoluteStats
+}
+
+/*
+Gets latest form of teams (or individuals, if `solo` is true) from `RawData` records as per filters i.e; form of each
+team is computed by `getLatestForm` (or `getLatestFormSolo`) from the matches that count for the team.
+*/
+func getLatestFormFiltered(records []RawData, filters []MatchFilter, nLatestGames int, solo bool, rules ScoringRules) []LatestForm {
+	records, keepSide := applyMatchFilters(records, filters)
+	getForm, names := getLatestForm, getUniqueTeamNames(records)
+	if solo {
+		getForm, names = getLatestFormSolo, getUniqueIndividualNames(records)
+	}
+	if keepSide == nil {
+		return getForm(records, nLatestGames, rules)
+	}
+	sliceLatestForm := []LatestForm{}
+	for _, name := range names {
+		for _, obj := range getForm(getRecordsForTeam(records, name, solo, keepSide), nLatestGames, rules) {
+			if obj.Team == name {
+				sliceLatestForm = append(sliceLatestForm, obj)
+			}
+		}
+	}
+	return sliceLatestForm
+}
+
+// Keeps only those `StatsAbs` objects having played at least one game (perspective filters may leave none)
+func removeStatsWithoutGames(sliceAbsoluteStats []StatsAbs) []StatsAbs {
+	sliceAbsoluteStatsPlayed := []StatsAbs{}
+	for _, obj := range sliceAbsoluteStats {
+		if obj.GamesPlayed > 0 {
+			sliceAbsoluteStatsPlayed = append(sliceAbsoluteStatsPlayed, obj)
+		}
+	}
+	return sliceAbsoluteStatsPlayed
+}
+
+// Returns true if any of the filters is a perspective filter
+func hasPerspectiveFilters(filters []MatchFilter) bool {
+	for _, filter := range filters {
+		if filter.KeepSide != nil {
+			return true
+		}
+	}
+	return false
+}
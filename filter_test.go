@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Gets `RawData` records of a small league, two matchdays of two matches each
+func getTestFilterRecords() []RawData {
+	date := func(day int) time.Time { return time.Date(2024, 8, day, 0, 0, 0, 0, time.UTC) }
+	return []RawData{
+		{HomeTeam: "Arsenal", HomeGoals: 2, AwayGoals: 0, AwayTeam: "Chelsea", Date: date(10)},
+		{HomeTeam: "Everton", HomeGoals: 1, AwayGoals: 1, AwayTeam: "Fulham", Date: date(10)},
+		{HomeTeam: "Chelsea", HomeGoals: 3, AwayGoals: 1, AwayTeam: "Everton", Date: date(17)},
+		{HomeTeam: "Fulham", HomeGoals: 0, AwayGoals: 1, AwayTeam: "Arsenal", Date: date(17)},
+	}
+}
+
+func TestParseMatchFiltersRejectsInvalidExpressions(t *testing.T) {
+	for _, expression := range []string{
+		"venue",
+		"venue=neutral",
+		"opponents=top-third",
+		"last-matchdays=0",
+		"last-matchdays=x",
+		"from=yesterday",
+		"teams=",
+		"colour=red",
+	} {
+		if _, err := parseMatchFilters(expression, defaultScoringRules); err == nil {
+			t.Errorf("%q: expected an error", expression)
+		}
+	}
+}
+
+func TestParseMatchFiltersKeepsMatches(t *testing.T) {
+	testCases := []struct {
+		expression string
+		want       int // Number of matches kept by league-wide filters
+	}{
+		{"", 4},
+		{" ; ", 4},
+		{"from=2024-08-17", 2},
+		{"to=2024-08-10", 2},
+		{"from=2024-08-11; to=2024-08-16", 0},
+		{"last-matchdays=1", 2},
+		{"last-matchdays=5", 4},
+		{"teams=Arsenal", 2},
+		{"teams=Arsenal, Everton", 4},
+		{"TEAMS=Chelsea; last-matchdays=1", 1},
+		{"venue=home", 4},
+	}
+	for _, testCase := range testCases {
+		filters, err := parseMatchFilters(testCase.expression, defaultScoringRules)
+		if err != nil {
+			t.Errorf("%q: %v", testCase.expression, err)
+			continue
+		}
+		if records, _ := applyMatchFilters(getTestFilterRecords(), filters); len(records) != testCase.want {
+			t.Errorf("%q: kept %d matches, want %d", testCase.expression, len(records), testCase.want)
+		}
+	}
+}
+
+func TestGetAbsoluteStatsFilteredByPerspective(t *testing.T) {
+	testCases := []struct {
+		expression        string
+		wantGames         map[string]int
+		wantArsenalPoints int
+	}{
+		{"venue=home", map[string]int{"Arsenal": 1, "Chelsea": 1, "Everton": 1, "Fulham": 1}, 3},
+		{"venue=away", map[string]int{"Arsenal": 1, "Chelsea": 1, "Everton": 1, "Fulham": 1}, 3},
+		// Table of all matches: Arsenal 6, Chelsea 3, Everton 1, Fulham 1 i.e; top half is Arsenal and Chelsea
+		{"opponents=top-half", map[string]int{"Arsenal": 1, "Chelsea": 1, "Everton": 1, "Fulham": 1}, 3},
+		{"venue=home; opponents=bottom-half", map[string]int{"Chelsea": 1, "Everton": 1}, 0},
+	}
+	for _, testCase := range testCases {
+		filters, err := parseMatchFilters(testCase.expression, defaultScoringRules)
+		if err != nil {
+			t.Fatalf("%q: %v", testCase.expression, err)
+		}
+		mapGamesByTeam, arsenalPoints := map[string]int{}, 0
+		for _, obj := range removeStatsWithoutGames(getAbsoluteStatsFiltered(getTestFilterRecords(), filters, defaultScoringRules)) {
+			mapGamesByTeam[obj.Team] = obj.GamesPlayed
+			if obj.Team == "Arsenal" {
+				arsenalPoints = obj.Points
+			}
+		}
+		if len(mapGamesByTeam) != len(testCase.wantGames) {
+			t.Errorf("%q: got games %v, want %v", testCase.expression, mapGamesByTeam, testCase.wantGames)
+			continue
+		}
+		for team, games := range testCase.wantGames {
+			if mapGamesByTeam[team] != games {
+				t.Errorf("%q: got games %v, want %v", testCase.expression, mapGamesByTeam, testCase.wantGames)
+				break
+			}
+		}
+		if arsenalPoints != testCase.wantArsenalPoints {
+			t.Errorf("%q: Arsenal got %d points, want %d", testCase.expression, arsenalPoints, testCase.wantArsenalPoints)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct to store a fixture i.e; a match yet to be played
+type Fixture struct {
+	Matchday int
+	HomeTeam string
+	AwayTeam string
+}
+
+/*
+Gets rounds of a single round-robin between `teams` by the circle method, wherein the first team stays put and the
+others rotate around it. Each round is a slice of pairs. If the number of teams is odd, the team paired with the
+empty string (bye) sits out that round.
+*/
+func getCircleMethodRounds(teams []string) [][][2]string {
+	circle := append([]string{}, teams...)
+	if len(circle)%2 == 1 {
+		circle = append([]string{""}, circle...) // The bye stays put, so rotating teams alternate venues evenly
+	}
+	numTeams := len(circle)
+	rounds := [][][2]string{}
+	for round := 0; round < numTeams-1; round++ {
+		pairs := [][2]string{}
+		for idx := 0; idx < numTeams/2; idx++ {
+			pairs = append(pairs, [2]string{circle[idx], circle[numTeams-1-idx]})
+		}
+		rounds = append(rounds, pairs)
+		// Rotate every team but the first, clockwise by one position
+		circle = append([]string{circle[0], circle[numTeams-1]}, circle[1:numTeams-1]...)
+	}
+	return rounds
+}
+
+/*
+Gets round-robin fixtures between `teams` by the circle method. Each team plays every other team once (or twice,
+home and away, if `double` is true). Home and away games are alternated so that each team's home and away counts
+differ by at most one, and the second half of a double round-robin mirrors the first with venues swapped.
+*/
+func getRoundRobinFixtures(teams []string, double bool) []Fixture {
+	rounds := getCircleMethodRounds(teams)
+	fixtures := []Fixture{}
+	for idxRound, pairs := range rounds {
+		for idxPair, pair := range pairs {
+			if pair[0] == "" || pair[1] == "" {
+				continue
+			}
+			homeTeam, awayTeam := pair[0], pair[1]
+			// The fixed team alternates venues every round, while the rotating teams alternate by their position
+			if (idxPair == 0 && idxRound%2 == 1) || (idxPair > 0 && idxPair%2 == 1) {
+				homeTeam, awayTeam = awayTeam, homeTeam
+			}
+			fixtures = append(fixtures, Fixture{Matchday: idxRound + 1, HomeTeam: homeTeam, AwayTeam: awayTeam})
+		}
+	}
+	if double {
+		firstLeg := fixtures
+		for _, fixture := range firstLeg {
+			fixtures = append(fixtures, Fixture{Matchday: fixture.Matchday + len(rounds), HomeTeam: fixture.AwayTeam, AwayTeam: fixture.HomeTeam})
+		}
+	}
+	return fixtures
+}
+
+// Gets name of a 2v2 team as per the 2v2 naming convention i.e; unique-names of both individuals, in alphabetical order
+func get2v2TeamName(individualA string, individualB string) string {
+	if individualA > individualB {
+		individualA, individualB = individualB, individualA
+	}
+	return individualA + individualB
+}
+
+/*
+Gets 2v2 rotation fixtures between `individuals`. Partnerships come from a round-robin between individuals
+(by the circle method), so every individual partners every other individual exactly once. Within each matchday,
+partnerships are matched up greedily so that individuals face the opponents they have faced least so far.
+Partnerships left over in a matchday (i.e; an odd number of them) are carried over to the next matchday, so every
+partnership plays once, except for one partnership if there's an odd number of them in all.
+*/
+func get2v2RotationFixtures(individuals []string) []Fixture {
+	mapCountByOpponentPair := map[string]int{} // Keyed by the 2v2 team-name of both individuals
+	getOpponentCount := func(teamA [2]string, teamB [2]string) int {
+		count := 0
+		for _, individualA := range teamA {
+			for _, individualB := range teamB {
+				count += mapCountByOpponentPair[get2v2TeamName(individualA, individualB)]
+			}
+		}
+		return count
+	}
+	isDisjoint := func(teamA [2]string, teamB [2]string) bool {
+		return teamA[0] != teamB[0] && teamA[0] != teamB[1] && teamA[1] != teamB[0] && teamA[1] != teamB[1]
+	}
+	fixtures := []Fixture{}
+	leftOver := [][2]string{} // Partnerships carried over from earlier matchdays, which are matched up first
+	for idxRound, pairs := range getCircleMethodRounds(individuals) {
+		partnerships := leftOver
+		for _, pair := range pairs {
+			if pair[0] != "" && pair[1] != "" {
+				partnerships = append(partnerships, pair)
+			}
+		}
+		for {
+			bestI, bestJ, bestCount := 0, 0, -1
+			for i := 0; i < len(partnerships); i++ {
+				for j := i + 1; j < len(partnerships); j++ {
+					count := getOpponentCount(partnerships[i], partnerships[j])
+					if isDisjoint(partnerships[i], partnerships[j]) && (bestCount == -1 || count < bestCount) {
+						bestI, bestJ, bestCount = i, j, count
+					}
+				}
+			}
+			if bestCount == -1 {
+				break // No partnerships left that can face each other
+			}
+			teamA, teamB := partnerships[bestI], partnerships[bestJ]
+			for _, individualA := range teamA {
+				for _, individualB := range teamB {
+					mapCountByOpponentPair[get2v2TeamName(individualA, individualB)]++
+				}
+			}
+			homeTeam, awayTeam := get2v2TeamName(teamA[0], teamA[1]), get2v2TeamName(teamB[0], teamB[1])
+			if len(fixtures)%2 == 1 {
+				homeTeam, awayTeam = awayTeam, homeTeam
+			}
+			fixtures = append(fixtures, Fixture{Matchday: idxRound + 1, HomeTeam: homeTeam, AwayTeam: awayTeam})
+			partnerships = append(partnerships[:bestJ], partnerships[bestJ+1:]...)
+			partnerships = append(partnerships[:bestI], partnerships[bestI+1:]...)
+		}
+		leftOver = partnerships
+	}
+	return fixtures
+}
+
+/*
+Saves fixtures to CSV file having the same columns as data files, with empty scores to be filled in later.
+Fixtures get a date if `startDate` is non-zero, with `daysBetweenMatchdays` days between consecutive matchdays.
+*/
+func saveFixturesToCsv(fixtures []Fixture, startDate time.Time, daysBetweenMatchdays int, filepath string) {
+	sliceStringifiedRecords := [][]string{{"HomeTeam", "HomeGoals", "AwayGoals", "AwayTeam", "Date"}}
+	for _, fixture := range fixtures {
+		date := ""
+		if !startDate.IsZero() {
+			date = startDate.AddDate(0, 0, (fixture.Matchday-1)*daysBetweenMatchdays).Format(dateLayouts[0])
+		}
+		sliceStringifiedRecords = append(sliceStringifiedRecords, []string{fixture.HomeTeam, "", "", fixture.AwayTeam, date})
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Splits comma-separated names, trimming whitespace and leaving out empty names
+func splitNames(names string) []string {
+	sliceNames := []string{}
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sliceNames = append(sliceNames, name)
+		}
+	}
+	return sliceNames
+}
+
+// Gets the first name appearing more than once in `names`. Returns false if all names are distinct
+func getDuplicateName(names []string) (string, bool) {
+	mapSeenByName := map[string]bool{}
+	for _, name := range names {
+		if mapSeenByName[name] {
+			return name, true
+		}
+		mapSeenByName[name] = true
+	}
+	return "", false
+}
+
+/*
+Exits if any of the unique-names of individuals wouldn't follow the 2v2 naming convention when part of a team-name,
+or if an individual is listed more than once
+*/
+func checkUniqueNames(individuals []string) {
+	re := regexp.MustCompile(`^[A-Z][^A-Z]*$`)
+	for _, individual := range individuals {
+		if !re.MatchString(individual) {
+			log.Fatalln("Invalid unique-name '" + individual + "'. It must start with a capital letter, followed by no other capital letters")
+		}
+	}
+	if individual, ok := getDuplicateName(individuals); ok {
+		log.Fatalln("Individual '" + individual + "' is listed more than once")
+	}
+}
+
+/*
+Command that generates fixtures i.e; a single/double round-robin between teams, or a 2v2 rotation between individuals.
+Fixtures are saved with empty scores, and can be dropped into the data folder once (partially) played.
+*/
+func runFixturesCommand(args []string) {
+	flagSet := flag.NewFlagSet("fixtures", flag.ExitOnError)
+	teams := flagSet.String("teams", "", "Comma-separated team-names, for a round-robin")
+	individuals := flagSet.String("individuals", "", "Comma-separated unique-names of individuals, for a 2v2 rotation")
+	double := flagSet.Bool("double", false, "Generate a double round-robin (home and away) instead of a single one")
+	startDate := flagSet.String("start-date", "", "Date of the first matchday (fixtures are undated if empty)")
+	daysBetweenMatchdays := flagSet.Int("days-between", 7, "Days between consecutive matchdays")
+	output := flagSet.String("output", "", "Path to the CSV file of fixtures (defaults to a file in the results folder)")
+	flagSet.Parse(args)
+
+	fixtures := []Fixture{}
+	filepath := pathResultsFolder + "/Fixtures.csv"
+	if *individuals != "" {
+		sliceIndividuals := splitNames(*individuals)
+		checkUniqueNames(sliceIndividuals)
+		if len(sliceIndividuals) < 4 {
+			log.Fatalln("A 2v2 rotation needs at least 4 individuals")
+		}
+		sort.Strings(sliceIndividuals)
+		fixtures = get2v2RotationFixtures(sliceIndividuals)
+		filepath = pathResultsFolder + "/Fixtures - 2v2.csv"
+	} else {
+		sliceTeams := splitNames(*teams)
+		if len(sliceTeams) < 2 {
+			log.Fatalln("A round-robin needs at least 2 teams. Use -teams or -individuals")
+		}
+		fixtures = getRoundRobinFixtures(sliceTeams, *double)
+	}
+	if *output != "" {
+		filepath = *output
+	}
+	date := time.Time{}
+	if *startDate != "" {
+		parsedDate, err := parseDate(*startDate)
+		if err != nil {
+			log.Fatalln("Error while parsing -start-date", err)
+		}
+		date = parsedDate
+	}
+	saveFixturesToCsv(fixtures, date, *daysBetweenMatchdays, filepath)
+	fmt.Println("Saved " + strconv.Itoa(len(fixtures)) + " fixtures to '" + filepath + "'")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+// Gets names of individuals following the 2v2 naming convention i.e; "Aa", "Bb", ...
+func getTestIndividuals(numIndividuals int) []string {
+	individuals := []string{}
+	for idx := 0; idx < numIndividuals; idx++ {
+		letter := string(rune('A' + idx))
+		individuals = append(individuals, letter+string(rune('a'+idx)))
+	}
+	return individuals
+}
+
+func TestGetRoundRobinFixtures(t *testing.T) {
+	for numTeams := 2; numTeams <= 9; numTeams++ {
+		teams := getTestIndividuals(numTeams)
+		for _, double := range []bool{false, true} {
+			mapCountByVenue := map[[2]string]int{}
+			for _, fixture := range getRoundRobinFixtures(teams, double) {
+				mapCountByVenue[[2]string{fixture.HomeTeam, fixture.AwayTeam}]++
+			}
+			for i, teamA := range teams {
+				for _, teamB := range teams[i+1:] {
+					home, away := mapCountByVenue[[2]string{teamA, teamB}], mapCountByVenue[[2]string{teamB, teamA}]
+					if (!double && home+away != 1) || (double && (home != 1 || away != 1)) {
+						t.Errorf("%d teams (double: %v): %s vs %s played %d/%d times at home/away", numTeams, double, teamA, teamB, home, away)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGet2v2RotationFixturesPlaysEveryPartnership(t *testing.T) {
+	re := regexp.MustCompile(`[A-Z][^A-Z]*`)
+	for numIndividuals := 4; numIndividuals <= 12; numIndividuals++ {
+		individuals := getTestIndividuals(numIndividuals)
+		mapCountByPartnership := map[string]int{}
+		for _, fixture := range get2v2RotationFixtures(individuals) {
+			mapCountByPartnership[fixture.HomeTeam]++
+			mapCountByPartnership[fixture.AwayTeam]++
+			for _, individual := range re.FindAllString(fixture.HomeTeam, -1) {
+				if individualInTeam(individual, fixture.AwayTeam) {
+					t.Errorf("%d individuals: %s plays for both sides of %s vs %s", numIndividuals, individual, fixture.HomeTeam, fixture.AwayTeam)
+				}
+			}
+		}
+		numPartnerships := numIndividuals * (numIndividuals - 1) / 2
+		numPlayed := 0
+		for i, individualA := range individuals {
+			for _, individualB := range individuals[i+1:] {
+				count := mapCountByPartnership[get2v2TeamName(individualA, individualB)]
+				if count > 1 {
+					t.Errorf("%d individuals: %s played %d times", numIndividuals, get2v2TeamName(individualA, individualB), count)
+				}
+				numPlayed += count
+			}
+		}
+		// Only one partnership may sit out, if there's an odd number of partnerships
+		if numPlayed != numPartnerships-numPartnerships%2 {
+			t.Errorf("%d individuals: %d of %d partnerships played", numIndividuals, numPlayed, numPartnerships)
+		}
+	}
+}
+
+func TestGetDuplicateName(t *testing.T) {
+	if name, ok := getDuplicateName([]string{"Ankur", "Gagan", "Nishant", "Gagan", "Ankur"}); !ok || name != "Gagan" {
+		t.Errorf("got %q (%v), want \"Gagan\" (true)", name, ok)
+	}
+	if name, ok := getDuplicateName(getTestIndividuals(6)); ok {
+		t.Errorf("distinct names: got duplicate %q", name)
+	}
+}
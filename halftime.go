@@ -0,0 +1,158 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Struct to store goals by half, and points won/lost from half-time positions
+type HalfTimeStats struct {
+	Rank                     int
+	Team                     string
+	GamesPlayed              int // Games having half-time goals
+	FirstHalfGoalsScored     int
+	FirstHalfGoalsAllowed    int
+	SecondHalfGoalsScored    int
+	SecondHalfGoalsAllowed   int
+	LeadingAtHalfTime        int
+	PointsFromLeading        int
+	PointsDroppedFromLeading int // Points not won in games led at half-time
+	LevelAtHalfTime          int
+	PointsFromLevel          int
+	TrailingAtHalfTime       int
+	PointsFromTrailing       int // Points won in games trailed at half-time
+	Comebacks                int // Games trailed at half-time and won
+	Collapses                int // Games led at half-time and lost
+	PointsSwing              int // PointsFromTrailing - PointsDroppedFromLeading
+}
+
+/*
+Method that gets slice of stringified elements of `HalfTimeStats` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `HalfTimeStats` struct to CSV file.
+*/
+func (obj HalfTimeStats) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	for _, value := range []int{
+		obj.GamesPlayed,
+		obj.FirstHalfGoalsScored, obj.FirstHalfGoalsAllowed,
+		obj.SecondHalfGoalsScored, obj.SecondHalfGoalsAllowed,
+		obj.LeadingAtHalfTime, obj.PointsFromLeading, obj.PointsDroppedFromLeading,
+		obj.LevelAtHalfTime, obj.PointsFromLevel,
+		obj.TrailingAtHalfTime, obj.PointsFromTrailing,
+		obj.Comebacks, obj.Collapses, obj.PointsSwing,
+	} {
+		values = append(values, strconv.Itoa(value))
+	}
+	return values
+}
+
+// Returns true if any of the `RawData` records has half-time goals
+func hasHalfTimeData(records []RawData) bool {
+	for _, record := range records {
+		if record.HasHalfTime {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Gets `RawData` records having half-time goals in place of full-time goals (and no extra time or penalty shootout).
+Records without half-time goals are left out. Used for computing the half-time table.
+*/
+func getHalfTimeRecords(records []RawData) []RawData {
+	halfTimeRecords := []RawData{}
+	for _, record := range records {
+		if record.HasHalfTime {
+			record.HomeGoals = record.HalfTimeHomeGoals
+			record.AwayGoals = record.HalfTimeAwayGoals
+			record.HasExtraTime, record.HomeGoalsAET, record.AwayGoalsAET = false, 0, 0
+			record.HasShootout, record.HomePenalties, record.AwayPenalties = false, 0, 0
+			halfTimeRecords = append(halfTimeRecords, record)
+		}
+	}
+	return halfTimeRecords
+}
+
+/*
+Gets slice of half-time stats from `RawData` records (only records having half-time goals are considered).
+Second-half goals, comebacks and collapses go by the regulation score, while points are won as in the table.
+Returns slice wherein each element of the slice is an object of the struct `HalfTimeStats`
+*/
+func getHalfTimeStats(records []RawData, rules ScoringRules) []HalfTimeStats {
+	halfTimeRecords := getHalfTimeRecords(records)
+	teams := getUniqueTeamNames(halfTimeRecords)
+	sliceHalfTimeStats := []HalfTimeStats{}
+	for _, team := range teams {
+		tempObj := HalfTimeStats{Team: team}
+		for _, record := range records {
+			if !record.HasHalfTime {
+				continue
+			}
+			var htFor, htAgainst, ftFor, ftAgainst int
+			if record.HomeTeam == team {
+				htFor, htAgainst = record.HalfTimeHomeGoals, record.HalfTimeAwayGoals
+				ftFor, ftAgainst = record.HomeGoals, record.AwayGoals
+			} else if record.AwayTeam == team {
+				htFor, htAgainst = record.HalfTimeAwayGoals, record.HalfTimeHomeGoals
+				ftFor, ftAgainst = record.AwayGoals, record.HomeGoals
+			} else {
+				continue
+			}
+			points := getPointsFromMatch(record, record.HomeTeam == team, rules)
+			tempObj.GamesPlayed++
+			tempObj.FirstHalfGoalsScored += htFor
+			tempObj.FirstHalfGoalsAllowed += htAgainst
+			tempObj.SecondHalfGoalsScored += ftFor - htFor
+			tempObj.SecondHalfGoalsAllowed += ftAgainst - htAgainst
+			if htFor > htAgainst {
+				tempObj.LeadingAtHalfTime++
+				tempObj.PointsFromLeading += points
+				tempObj.PointsDroppedFromLeading += 3 - points
+				if ftFor < ftAgainst {
+					tempObj.Collapses++
+				}
+			} else if htFor == htAgainst {
+				tempObj.LevelAtHalfTime++
+				tempObj.PointsFromLevel += points
+			} else {
+				tempObj.TrailingAtHalfTime++
+				tempObj.PointsFromTrailing += points
+				if ftFor > ftAgainst {
+					tempObj.Comebacks++
+				}
+			}
+		}
+		tempObj.PointsSwing = tempObj.PointsFromTrailing - tempObj.PointsDroppedFromLeading
+		sliceHalfTimeStats = append(sliceHalfTimeStats, tempObj)
+	}
+	return sliceHalfTimeStats
+}
+
+// Sorts half-time stats based on `PointsSwing`, and attaches ranking
+func sortAndRankHalfTimeStats(sliceHalfTimeStats []HalfTimeStats) []HalfTimeStats {
+	sort.SliceStable(sliceHalfTimeStats, func(i, j int) bool {
+		return sliceHalfTimeStats[i].PointsSwing > sliceHalfTimeStats[j].PointsSwing
+	})
+	for idx := range sliceHalfTimeStats {
+		sliceHalfTimeStats[idx].Rank = idx + 1
+	}
+	return sliceHalfTimeStats
+}
+
+// Saves slice having objects of `HalfTimeStats` struct to CSV file
+func saveHalfTimeStatsToCsv(sliceData []HalfTimeStats, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&HalfTimeStats{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Seed used for bootstrap resampling, so that intervals are reproducible across runs
+const bootstrapSeed = 42
+
+// Struct to store normalized tabular statistics along with the lower/upper bounds of their confidence intervals
+type StatsNormInterval struct {
+	Rank        int
+	Team        string
+	GamesPlayed int
+	PPG         float64
+	PPGLow      float64
+	PPGHigh     float64
+	GDPG        float64
+	GDPGLow     float64
+	GDPGHigh    float64
+	GSPG        float64
+	GSPGLow     float64
+	GSPGHigh    float64
+	GAPG        float64
+	GAPGLow     float64
+	GAPGHigh    float64
+	WinPct      float64
+	WinPctLow   float64
+	WinPctHigh  float64
+	LossPct     float64
+	LossPctLow  float64
+	LossPctHigh float64
+	DrawPct     float64
+	DrawPctLow  float64
+	DrawPctHigh float64
+	CsPct       float64
+	CsPctLow    float64
+	CsPctHigh   float64
+	CsaPct      float64
+	CsaPctLow   float64
+	CsaPctHigh  float64
+}
+
+// Struct to store per-game samples of a team/individual. Used for bootstrapping per-game rates
+type PerGameSamples struct {
+	Points       []float64
+	GoalDiff     []float64
+	GoalsScored  []float64
+	GoalsAllowed []float64
+}
+
+/*
+Method that gets slice of stringified elements of `StatsNormInterval` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `StatsNormInterval` struct to CSV file.
+*/
+func (obj StatsNormInterval) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	for _, value := range []float64{
+		obj.PPG, obj.PPGLow, obj.PPGHigh,
+		obj.GDPG, obj.GDPGLow, obj.GDPGHigh,
+		obj.GSPG, obj.GSPGLow, obj.GSPGHigh,
+		obj.GAPG, obj.GAPGLow, obj.GAPGHigh,
+		obj.WinPct, obj.WinPctLow, obj.WinPctHigh,
+		obj.LossPct, obj.LossPctLow, obj.LossPctHigh,
+		obj.DrawPct, obj.DrawPctLow, obj.DrawPctHigh,
+		obj.CsPct, obj.CsPctLow, obj.CsPctHigh,
+		obj.CsaPct, obj.CsaPctLow, obj.CsaPctHigh,
+	} {
+		values = append(values, fmt.Sprintf("%g", value))
+	}
+	return values
+}
+
+// Gets the two-sided z-score for the given confidence level (eg: 0.95 -> 1.96). Level must be between 0 and 1 (exclusive)
+func getZScore(confidenceLevel float64) float64 {
+	if confidenceLevel <= 0 || confidenceLevel >= 1 {
+		log.Fatalln("Confidence level " + strconv.FormatFloat(confidenceLevel, 'f', -1, 64) + " is not between 0 and 1")
+	}
+	return math.Sqrt2 * math.Erfinv(confidenceLevel)
+}
+
+/*
+Gets Wilson score interval (as percentages) for `successes` out of `trials`.
+Unlike the normal approximation, it behaves sensibly for small samples and for proportions near 0% or 100%.
+*/
+func getWilsonInterval(successes int, trials int, confidenceLevel float64) (float64, float64) {
+	if trials == 0 {
+		return 0, 0
+	}
+	hundred := 100.0
+	z := getZScore(confidenceLevel)
+	n := float64(trials)
+	p := float64(successes) / n
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	halfWidth := (z / denominator) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	low := math.Max(0, center-halfWidth)
+	high := math.Min(1, center+halfWidth)
+	return round(low*hundred, 2), round(high*hundred, 2)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+/*
+Gets percentile bootstrap interval for the mean of `samples`.
+Resamples with replacement `numResamples` times, and picks the appropriate percentiles of the resampled means.
+*/
+func getBootstrapInterval(samples []float64, numResamples int, confidenceLevel float64, rng *rand.Rand) (float64, float64) {
+	if len(samples) == 0 || numResamples <= 0 {
+		return 0, 0
+	}
+	resampledMeans := make([]float64, numResamples)
+	for i := 0; i < numResamples; i++ {
+		sum := 0.0
+		for j := 0; j < len(samples); j++ {
+			sum += samples[rng.Intn(len(samples))]
+		}
+		resampledMeans[i] = sum / float64(len(samples))
+	}
+	sort.Float64s(resampledMeans)
+	alpha := (1 - confidenceLevel) / 2
+	idxLow := int(math.Floor(alpha * float64(numResamples-1)))
+	idxHigh := int(math.Ceil((1 - alpha) * float64(numResamples-1)))
+	return resampledMeans[idxLow], resampledMeans[idxHigh]
+}
+
+/*
+Gets per-game samples (points, goal difference, goals scored, goals allowed) of a team/individual from `RawData` records.
+If `solo` is true, `team` is treated as an individual who may appear in any 2v2 team.
+*/
+func getPerGameSamples(records []RawData, team string, solo bool, rules ScoringRules) PerGameSamples {
+	samples := PerGameSamples{}
+	for _, record := range records {
+		isHome, isAway := record.HomeTeam == team, record.AwayTeam == team
+		if solo {
+			isHome, isAway = individualInTeam(team, record.HomeTeam), individualInTeam(team, record.AwayTeam)
+		}
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		goalsFor, goalsAgainst := 0, 0
+		if isHome {
+			goalsFor, goalsAgainst = homeGoals, awayGoals
+		} else if isAway {
+			goalsFor, goalsAgainst = awayGoals, homeGoals
+		} else {
+			continue
+		}
+		samples.Points = append(samples.Points, float64(getPointsFromMatch(record, isHome, rules)))
+		samples.GoalDiff = append(samples.GoalDiff, float64(goalsFor-goalsAgainst))
+		samples.GoalsScored = append(samples.GoalsScored, float64(goalsFor))
+		samples.GoalsAllowed = append(samples.GoalsAllowed, float64(goalsAgainst))
+	}
+	return samples
+}
+
+/*
+Gets slice of normalized stats with confidence intervals, in the same order (and with the same ranking) as `sliceNormStats`.
+Percentages get Wilson score intervals, whereas per-game rates get bootstrap intervals.
+If `solo` is true, stats are considered to be of individuals rather than teams.
+*/
+func getNormStatsIntervals(records []RawData, sliceAbsStats []StatsAbs, sliceNormStats []StatsNorm, options PipelineOptions, solo bool) []StatsNormInterval {
+	mapAbsStatsByTeam := map[string]StatsAbs{}
+	for _, obj := range sliceAbsStats {
+		mapAbsStatsByTeam[obj.Team] = obj
+	}
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	conf := options.ConfidenceLevel
+	numResamples := options.BootstrapSamples
+	sliceNormStatsIntervals := []StatsNormInterval{}
+	for _, objNorm := range sliceNormStats {
+		objAbs := mapAbsStatsByTeam[objNorm.Team]
+		gp := objAbs.GamesPlayed
+		samples := getPerGameSamples(records, objNorm.Team, solo, options.ScoringRules)
+		ppgLow, ppgHigh := getBootstrapInterval(samples.Points, numResamples, conf, rng)
+		gdpgLow, gdpgHigh := getBootstrapInterval(samples.GoalDiff, numResamples, conf, rng)
+		gspgLow, gspgHigh := getBootstrapInterval(samples.GoalsScored, numResamples, conf, rng)
+		gapgLow, gapgHigh := getBootstrapInterval(samples.GoalsAllowed, numResamples, conf, rng)
+		winPctLow, winPctHigh := getWilsonInterval(objAbs.Wins, gp, conf)
+		lossPctLow, lossPctHigh := getWilsonInterval(objAbs.Losses, gp, conf)
+		drawPctLow, drawPctHigh := getWilsonInterval(objAbs.Draws, gp, conf)
+		csPctLow, csPctHigh := getWilsonInterval(objAbs.CleanSheets, gp, conf)
+		csaPctLow, csaPctHigh := getWilsonInterval(objAbs.CleanSheetsAgainst, gp, conf)
+		tempObj := StatsNormInterval{
+			Rank:        objNorm.Rank,
+			Team:        objNorm.Team,
+			GamesPlayed: objNorm.GamesPlayed,
+			PPG:         objNorm.PPG,
+			PPGLow:      round(ppgLow, 4),
+			PPGHigh:     round(ppgHigh, 4),
+			GDPG:        objNorm.GDPG,
+			GDPGLow:     round(gdpgLow, 3),
+			GDPGHigh:    round(gdpgHigh, 3),
+			GSPG:        objNorm.GSPG,
+			GSPGLow:     round(gspgLow, 3),
+			GSPGHigh:    round(gspgHigh, 3),
+			GAPG:        objNorm.GAPG,
+			GAPGLow:     round(gapgLow, 3),
+			GAPGHigh:    round(gapgHigh, 3),
+			WinPct:      objNorm.WinPct,
+			WinPctLow:   winPctLow,
+			WinPctHigh:  winPctHigh,
+			LossPct:     objNorm.LossPct,
+			LossPctLow:  lossPctLow,
+			LossPctHigh: lossPctHigh,
+			DrawPct:     objNorm.DrawPct,
+			DrawPctLow:  drawPctLow,
+			DrawPctHigh: drawPctHigh,
+			CsPct:       objNorm.CsPct,
+			CsPctLow:    csPctLow,
+			CsPctHigh:   csPctHigh,
+			CsaPct:      objNorm.CsaPct,
+			CsaPctLow:   csaPctLow,
+			CsaPctHigh:  csaPctHigh,
+		}
+		sliceNormStatsIntervals = append(sliceNormStatsIntervals, tempObj)
+	}
+	return sliceNormStatsIntervals
+}
+
+// Saves slice having objects of `StatsNormInterval` struct to CSV file
+func saveNormIntervalsToCsv(sliceData []StatsNormInterval, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&StatsNormInterval{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
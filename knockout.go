@@ -0,0 +1,227 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// Struct to store a knockout tie, along with the tie that its winner goes on to play
+type KnockoutTie struct {
+	Tie      int // Number of the tie, in the order of the data file
+	Round    int // 1 for a team's first knockout tie, and one more than the furthest round reached by either side otherwise
+	Stage    string
+	HomeTeam string
+	AwayTeam string
+	Score    string // Eg: "1-1 (2-2 aet, 4-3 pens)"
+	Winner   string
+	NextTie  int // Number of the next tie played by the winner. 0 if there isn't one
+}
+
+/*
+Method that gets slice of stringified elements of `KnockoutTie` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `KnockoutTie` struct to CSV file.
+*/
+func (obj KnockoutTie) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Tie))
+	values = append(values, strconv.Itoa(obj.Round))
+	values = append(values, obj.Stage)
+	values = append(values, obj.HomeTeam)
+	values = append(values, obj.AwayTeam)
+	values = append(values, obj.Score)
+	values = append(values, obj.Winner)
+	values = append(values, strconv.Itoa(obj.NextTie))
+	return values
+}
+
+/*
+Gets score of a match used in tables, as per the scoring rules i.e; the score after extra time if the match went to
+extra time and extra time is counted in tables, and the regulation score otherwise.
+*/
+func getScoreForTable(record RawData, rules ScoringRules) (int, int) {
+	if rules.CountExtraTime && record.HasExtraTime {
+		return record.HomeGoalsAET, record.AwayGoalsAET
+	}
+	return record.HomeGoals, record.AwayGoals
+}
+
+// Gets score after extra time if the match went to extra time, and the regulation score otherwise
+func getScoreAfterExtraTime(record RawData) (int, int) {
+	if record.HasExtraTime {
+		return record.HomeGoalsAET, record.AwayGoalsAET
+	}
+	return record.HomeGoals, record.AwayGoals
+}
+
+/*
+Gets winner of a match, going by the penalty shootout, else the score after extra time, else the regulation score.
+Returns empty string if the match was drawn.
+*/
+func getMatchWinner(record RawData) string {
+	homeGoals, awayGoals := getScoreAfterExtraTime(record)
+	if record.HasShootout {
+		homeGoals, awayGoals = record.HomePenalties, record.AwayPenalties
+	}
+	if homeGoals > awayGoals {
+		return record.HomeTeam
+	} else if homeGoals < awayGoals {
+		return record.AwayTeam
+	}
+	return ""
+}
+
+/*
+Gets winner of a match as per the scoring rules i.e; going by the score used in tables, else by the penalty shootout
+if shootouts are decided in tables. Returns empty string if the match counts as a draw.
+*/
+func getMatchWinnerForTable(record RawData, rules ScoringRules) string {
+	homeGoals, awayGoals := getScoreForTable(record, rules)
+	if homeGoals > awayGoals {
+		return record.HomeTeam
+	} else if homeGoals < awayGoals {
+		return record.AwayTeam
+	}
+	if record.HasShootout && rules.ShootoutMode == shootoutModePoints {
+		return getMatchWinner(record)
+	}
+	return ""
+}
+
+// Gets scoreline of a match, noting extra time and penalty shootout if any. Eg: "1-1 (2-2 aet, 4-3 pens)"
+func getScoreline(record RawData) string {
+	homeGoals, awayGoals := record.HomeGoals, record.AwayGoals
+	scoreline := strconv.Itoa(homeGoals) + "-" + strconv.Itoa(awayGoals)
+	notes := []string{}
+	if record.HasExtraTime {
+		if homeGoals == record.HomeGoalsAET && awayGoals == record.AwayGoalsAET {
+			scoreline += " aet"
+		} else {
+			notes = append(notes, strconv.Itoa(record.HomeGoalsAET)+"-"+strconv.Itoa(record.AwayGoalsAET)+" aet")
+		}
+	}
+	if record.HasShootout {
+		notes = append(notes, strconv.Itoa(record.HomePenalties)+"-"+strconv.Itoa(record.AwayPenalties)+" pens")
+	}
+	if len(notes) > 0 {
+		scoreline += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return scoreline
+}
+
+// Returns true if the stage is a group stage (eg: "Group A")
+func isGroupStage(stage string) bool {
+	return strings.HasPrefix(strings.ToLower(stage), "group")
+}
+
+/*
+Gets knockout matches from `RawData` records. If records have stages, knockout matches are the ones having
+a non-group stage. Otherwise, every match is taken to be a knockout match.
+*/
+func getKnockoutRecords(records []RawData) []RawData {
+	hasStages := hasStageData(records)
+	knockoutRecords := []RawData{}
+	for _, record := range records {
+		if isKnockoutRecord(record, hasStages) {
+			knockoutRecords = append(knockoutRecords, record)
+		}
+	}
+	return knockoutRecords
+}
+
+// Returns true if any of the `RawData` records has a stage
+func hasStageData(records []RawData) bool {
+	for _, record := range records {
+		if record.Stage != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the match is a knockout match, given whether its data file has stages (see `getKnockoutRecords`)
+func isKnockoutRecord(record RawData, hasStages bool) bool {
+	return !hasStages || (record.Stage != "" && !isGroupStage(record.Stage))
+}
+
+// Returns true if any of the `RawData` records went to extra time or penalties, or belongs to a knockout stage
+func hasKnockoutData(records []RawData) bool {
+	for _, record := range records {
+		if record.HasExtraTime || record.HasShootout || (record.Stage != "" && !isGroupStage(record.Stage)) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Gets league matches from `RawData` records i.e; the ones that aren't knockout matches (see `getKnockoutRecords`).
+Records of data files without knockout data (see `hasKnockoutData`) are all league matches.
+*/
+func getLeagueRecords(records []RawData) []RawData {
+	if !hasKnockoutData(records) {
+		return records
+	}
+	hasStages := hasStageData(records)
+	leagueRecords := []RawData{}
+	for _, record := range records {
+		if !isKnockoutRecord(record, hasStages) {
+			leagueRecords = append(leagueRecords, record)
+		}
+	}
+	return leagueRecords
+}
+
+/*
+Gets knockout bracket from `RawData` records (which must be in chronological order). Each match is taken to be a tie,
+and the winner of each tie is followed to the next tie that they play.
+Returns slice wherein each element of the slice is an object of the struct `KnockoutTie`
+*/
+func getKnockoutBracket(records []RawData) []KnockoutTie {
+	knockoutRecords := getKnockoutRecords(records)
+	sliceKnockoutTies := []KnockoutTie{}
+	mapRoundsWonByTeam := map[string]int{}
+	mapLatestTieByTeam := map[string]int{} // Index of latest tie won by team
+	for idx, record := range knockoutRecords {
+		round := mapRoundsWonByTeam[record.HomeTeam]
+		if mapRoundsWonByTeam[record.AwayTeam] > round {
+			round = mapRoundsWonByTeam[record.AwayTeam]
+		}
+		for _, team := range []string{record.HomeTeam, record.AwayTeam} {
+			if idxPreviousTie, ok := mapLatestTieByTeam[team]; ok {
+				sliceKnockoutTies[idxPreviousTie].NextTie = idx + 1
+				delete(mapLatestTieByTeam, team)
+			}
+		}
+		winner := getMatchWinner(record)
+		tempObj := KnockoutTie{
+			Tie:      idx + 1,
+			Round:    round + 1,
+			Stage:    record.Stage,
+			HomeTeam: record.HomeTeam,
+			AwayTeam: record.AwayTeam,
+			Score:    getScoreline(record),
+			Winner:   winner,
+		}
+		sliceKnockoutTies = append(sliceKnockoutTies, tempObj)
+		if winner != "" {
+			mapRoundsWonByTeam[winner] = round + 1
+			mapLatestTieByTeam[winner] = idx
+		}
+	}
+	return sliceKnockoutTies
+}
+
+// Saves slice having objects of `KnockoutTie` struct to CSV file
+func saveKnockoutBracketToCsv(sliceData []KnockoutTie, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&KnockoutTie{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
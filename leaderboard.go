@@ -0,0 +1,447 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+/*
+Struct to store a custom leaderboard, parsed from a query (see `parseLeaderboardQuery`) i.e;
+"rank by GSPG where GamesPlayed >= 10, show Team, GSPG, CsPct, top 5"
+*/
+type Leaderboard struct {
+	Name       string
+	Query      string
+	RankBy     string // Ranking expression, as written in the query
+	Ascending  bool   // Lowest value ranks first (rankings are descending by default)
+	Fields     []string
+	Top        int // Number of rows kept (all if 0)
+	rankValue  func(row map[string]interface{}) float64
+	conditions []func(row map[string]interface{}) bool
+}
+
+// Struct to store a token of a leaderboard query i.e; a keyword/field name, number, string, operator or comma
+type queryToken struct {
+	kind  string // One of "word", "number", "string", "symbol"
+	value string
+}
+
+// Struct to store the state of parsing a leaderboard query
+type queryParser struct {
+	tokens    []queryToken
+	position  int
+	rowSample map[string]interface{} // Fields available to queries, with zero values of their types
+}
+
+// Keywords of leaderboard queries (case-insensitive), which can't be used as field names
+var queryKeywords = []string{"rank", "by", "where", "and", "show", "top", "asc", "desc"}
+
+// Splits a leaderboard query into tokens
+func tokenizeLeaderboardQuery(query string) ([]queryToken, error) {
+	re := regexp.MustCompile(`\s*(?:([A-Za-z_][A-Za-z0-9_]*)|([0-9]+(?:\.[0-9]+)?)|"([^"]*)"|(>=|<=|!=|==|[-+*/(),<>=]))`)
+	tokens := []queryToken{}
+	rest := query
+	for strings.TrimSpace(rest) != "" {
+		match := re.FindStringSubmatchIndex(rest)
+		if match == nil || match[0] != 0 {
+			return nil, errors.New("unexpected '" + strings.TrimSpace(rest) + "'")
+		}
+		if match[2] != -1 {
+			tokens = append(tokens, queryToken{kind: "word", value: rest[match[2]:match[3]]})
+		} else if match[4] != -1 {
+			tokens = append(tokens, queryToken{kind: "number", value: rest[match[4]:match[5]]})
+		} else if match[6] != -1 {
+			tokens = append(tokens, queryToken{kind: "string", value: rest[match[6]:match[7]]})
+		} else {
+			tokens = append(tokens, queryToken{kind: "symbol", value: rest[match[8]:match[9]]})
+		}
+		rest = rest[match[1]:]
+	}
+	return tokens, nil
+}
+
+// Gets the next token without consuming it. Returns an empty token at the end of the query
+func (parser *queryParser) peek() queryToken {
+	if parser.position >= len(parser.tokens) {
+		return queryToken{}
+	}
+	return parser.tokens[parser.position]
+}
+
+// Returns true (and consumes the token) if the next token is the given keyword
+func (parser *queryParser) acceptKeyword(keyword string) bool {
+	token := parser.peek()
+	if token.kind == "word" && strings.EqualFold(token.value, keyword) {
+		parser.position++
+		return true
+	}
+	return false
+}
+
+// Returns true (and consumes the token) if the next token is the given symbol
+func (parser *queryParser) acceptSymbol(symbol string) bool {
+	token := parser.peek()
+	if token.kind == "symbol" && token.value == symbol {
+		parser.position++
+		return true
+	}
+	return false
+}
+
+// Parses a field name. Returns an error if the field isn't an attribute of `StatsAbs`/`StatsNorm`
+func (parser *queryParser) parseField() (string, error) {
+	token := parser.peek()
+	if token.kind != "word" || stringInSlice(strings.ToLower(token.value), queryKeywords) {
+		return "", errors.New("expected a field name, found '" + token.value + "'")
+	}
+	if _, ok := parser.rowSample[token.value]; !ok {
+		return "", errors.New("unknown field '" + token.value + "'")
+	}
+	parser.position++
+	return token.value, nil
+}
+
+// Parses an arithmetic expression i.e; "Wins * 3 + Draws", being terms separated by "+" or "-"
+func (parser *queryParser) parseExpression() (func(row map[string]interface{}) float64, error) {
+	left, err := parser.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if parser.acceptSymbol("+") {
+			right, err := parser.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			leftOperand := left
+			left = func(row map[string]interface{}) float64 { return leftOperand(row) + right(row) }
+		} else if parser.acceptSymbol("-") {
+			right, err := parser.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			leftOperand := left
+			left = func(row map[string]interface{}) float64 { return leftOperand(row) - right(row) }
+		} else {
+			return left, nil
+		}
+	}
+}
+
+// Parses a term of an arithmetic expression, being factors separated by "*" or "/"
+func (parser *queryParser) parseTerm() (func(row map[string]interface{}) float64, error) {
+	left, err := parser.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if parser.acceptSymbol("*") {
+			right, err := parser.parseFactor()
+			if err != nil {
+				return nil, err
+			}
+			leftOperand := left
+			left = func(row map[string]interface{}) float64 { return leftOperand(row) * right(row) }
+		} else if parser.acceptSymbol("/") {
+			right, err := parser.parseFactor()
+			if err != nil {
+				return nil, err
+			}
+			leftOperand := left
+			left = func(row map[string]interface{}) float64 { return leftOperand(row) / right(row) }
+		} else {
+			return left, nil
+		}
+	}
+}
+
+// Parses a factor of an arithmetic expression i.e; a number, a numeric field, a negated factor, or a bracketed expression
+func (parser *queryParser) parseFactor() (func(row map[string]interface{}) float64, error) {
+	token := parser.peek()
+	if parser.acceptSymbol("-") {
+		factor, err := parser.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return func(row map[string]interface{}) float64 { return -factor(row) }, nil
+	}
+	if parser.acceptSymbol("(") {
+		expression, err := parser.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if !parser.acceptSymbol(")") {
+			return nil, errors.New("expected ')'")
+		}
+		return expression, nil
+	}
+	if token.kind == "number" {
+		parser.position++
+		number, _ := strconv.ParseFloat(token.value, 64)
+		return func(row map[string]interface{}) float64 { return number }, nil
+	}
+	field, err := parser.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if _, isText := parser.rowSample[field].(string); isText {
+		return nil, errors.New("field '" + field + "' isn't numeric")
+	}
+	return func(row map[string]interface{}) float64 { return getNumericValue(row[field]) }, nil
+}
+
+// Parses a condition i.e; "GamesPlayed >= 10", or "Team != "Arsenal"" for text fields
+func (parser *queryParser) parseCondition() (func(row map[string]interface{}) bool, error) {
+	token := parser.peek()
+	if token.kind == "word" {
+		if _, isText := parser.rowSample[token.value].(string); isText {
+			parser.position++
+			isEqual := parser.acceptSymbol("=") || parser.acceptSymbol("==")
+			if !isEqual && !parser.acceptSymbol("!=") {
+				return nil, errors.New("text field '" + token.value + "' can only be compared with '=' or '!='")
+			}
+			value := parser.peek()
+			if value.kind != "string" {
+				return nil, errors.New("text field '" + token.value + "' must be compared with a quoted string")
+			}
+			parser.position++
+			return func(row map[string]interface{}) bool { return (row[token.value] == value.value) == isEqual }, nil
+		}
+	}
+	left, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	operator := parser.peek()
+	if operator.kind != "symbol" || !stringInSlice(operator.value, []string{">=", "<=", ">", "<", "=", "==", "!="}) {
+		return nil, errors.New("expected a comparison operator, found '" + operator.value + "'")
+	}
+	parser.position++
+	right, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return func(row map[string]interface{}) bool {
+		leftValue, rightValue := left(row), right(row)
+		switch operator.value {
+		case ">=":
+			return leftValue >= rightValue
+		case "<=":
+			return leftValue <= rightValue
+		case ">":
+			return leftValue > rightValue
+		case "<":
+			return leftValue < rightValue
+		case "!=":
+			return leftValue != rightValue
+		}
+		return leftValue == rightValue
+	}, nil
+}
+
+// Gets numeric value of a field of `StatsAbs`/`StatsNorm`
+func getNumericValue(value interface{}) float64 {
+	switch number := value.(type) {
+	case int:
+		return float64(number)
+	case float64:
+		return number
+	}
+	return math.NaN()
+}
+
+// Gets map of field name -> value having fields of both `StatsAbs` and `StatsNorm` (except their ranks)
+func getLeaderboardRow(objAbs StatsAbs, objNorm StatsNorm) map[string]interface{} {
+	row := structs.Map(objAbs)
+	for field, value := range structs.Map(objNorm) {
+		row[field] = value
+	}
+	delete(row, "Rank")
+	return row
+}
+
+/*
+Parses a leaderboard query, being clauses in any order (optionally separated by commas):
+"rank by EXPRESSION [asc|desc]" (required), "where CONDITION [and CONDITION]...", "show FIELD, FIELD..." and "top N".
+Expressions are arithmetic over numeric fields of `StatsAbs` and `StatsNorm` (i.e; "Wins * 3 + Draws"), and
+conditions compare two expressions (or a text field with a quoted string).
+*/
+func parseLeaderboardQuery(name string, query string) (Leaderboard, error) {
+	leaderboard := Leaderboard{Name: name, Query: query}
+	tokens, err := tokenizeLeaderboardQuery(query)
+	if err != nil {
+		return leaderboard, err
+	}
+	parser := &queryParser{tokens: tokens, rowSample: getLeaderboardRow(StatsAbs{}, StatsNorm{})}
+	for parser.position < len(parser.tokens) {
+		if parser.acceptSymbol(",") {
+			continue
+		}
+		if parser.acceptKeyword("rank") {
+			if !parser.acceptKeyword("by") {
+				return leaderboard, errors.New("expected 'by' after 'rank'")
+			}
+			start := parser.position
+			if leaderboard.rankValue, err = parser.parseExpression(); err != nil {
+				return leaderboard, err
+			}
+			words := []string{}
+			for _, token := range parser.tokens[start:parser.position] {
+				words = append(words, token.value)
+			}
+			leaderboard.RankBy = strings.Join(words, " ")
+			if parser.acceptKeyword("asc") {
+				leaderboard.Ascending = true
+			} else {
+				parser.acceptKeyword("desc")
+			}
+		} else if parser.acceptKeyword("where") {
+			for {
+				condition, err := parser.parseCondition()
+				if err != nil {
+					return leaderboard, err
+				}
+				leaderboard.conditions = append(leaderboard.conditions, condition)
+				if !parser.acceptKeyword("and") {
+					break
+				}
+			}
+		} else if parser.acceptKeyword("show") {
+			for {
+				field, err := parser.parseField()
+				if err != nil {
+					return leaderboard, err
+				}
+				leaderboard.Fields = append(leaderboard.Fields, field)
+				isNextField := parser.position+1 < len(parser.tokens) && parser.tokens[parser.position+1].kind == "word" &&
+					!stringInSlice(strings.ToLower(parser.tokens[parser.position+1].value), queryKeywords)
+				if !isNextField || !parser.acceptSymbol(",") {
+					break
+				}
+			}
+		} else if parser.acceptKeyword("top") {
+			token := parser.peek()
+			top, err := strconv.Atoi(token.value)
+			if token.kind != "number" || err != nil || top < 1 {
+				return leaderboard, errors.New("expected a whole number after 'top'")
+			}
+			leaderboard.Top = top
+			parser.position++
+		} else {
+			return leaderboard, errors.New("unexpected '" + parser.peek().value + "'")
+		}
+	}
+	if leaderboard.rankValue == nil {
+		return leaderboard, errors.New("missing 'rank by' clause")
+	}
+	if len(leaderboard.Fields) == 0 {
+		leaderboard.Fields = []string{"Team", leaderboard.RankBy, "GamesPlayed"}
+		if leaderboard.RankBy == "GamesPlayed" {
+			leaderboard.Fields = []string{"Team", "GamesPlayed"}
+		}
+	}
+	return leaderboard, nil
+}
+
+/*
+Gets stringified records of a leaderboard (first record being the header) from slice of absolute stats i.e; teams
+meeting all conditions, ranked by the ranking expression. Ties keep the order of `sliceAbsStats`.
+*/
+func getLeaderboardRecords(leaderboard Leaderboard, sliceAbsStats []StatsAbs, options PipelineOptions) [][]string {
+	sliceNormStats := getNormalizedStats(sliceAbsStats)
+	sliceNormStats = shrinkPpgTowardsLeagueMean(sliceNormStats, sliceAbsStats, options.PriorStrength)
+	rows := []map[string]interface{}{}
+	for idx, objAbs := range sliceAbsStats {
+		row := getLeaderboardRow(objAbs, sliceNormStats[idx])
+		isKept := true
+		for _, condition := range leaderboard.conditions {
+			isKept = isKept && condition(row)
+		}
+		if isKept {
+			row[leaderboard.RankBy] = round(leaderboard.rankValue(row), 4)
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		valueOfI, valueOfJ := rows[i][leaderboard.RankBy].(float64), rows[j][leaderboard.RankBy].(float64)
+		if math.IsNaN(valueOfJ) {
+			return !math.IsNaN(valueOfI)
+		}
+		if leaderboard.Ascending {
+			return valueOfI < valueOfJ
+		}
+		return valueOfI > valueOfJ
+	})
+	if leaderboard.Top > 0 && len(rows) > leaderboard.Top {
+		rows = rows[:leaderboard.Top]
+	}
+	sliceStringifiedRecords := [][]string{append([]string{"Rank"}, leaderboard.Fields...)}
+	for idx, row := range rows {
+		record := []string{strconv.Itoa(idx + 1)}
+		for _, field := range leaderboard.Fields {
+			switch value := row[field].(type) {
+			case int:
+				record = append(record, strconv.Itoa(value))
+			case float64:
+				record = append(record, fmt.Sprintf("%g", value))
+			default:
+				record = append(record, fmt.Sprint(value))
+			}
+		}
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	return sliceStringifiedRecords
+}
+
+/*
+Gets leaderboards from the `-leaderboard` query (named "Custom"), and from the leaderboards file having columns
+Name, Query. Exits if any query is invalid.
+*/
+func loadLeaderboards(options PipelineOptions) []Leaderboard {
+	mapQueryByName, names := map[string]string{}, []string{}
+	if options.Leaderboard != "" {
+		mapQueryByName["Custom"], names = options.Leaderboard, append(names, "Custom")
+	}
+	if options.LeaderboardsFile != "" {
+		csvfile, err := os.Open(options.LeaderboardsFile)
+		if err != nil {
+			log.Fatalln("Couldn't open the leaderboards file", err)
+		}
+		defer csvfile.Close()
+		r := csv.NewReader(csvfile)
+		lineCount := 0
+		for {
+			lineCount++
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			if lineCount != 1 {
+				mapQueryByName[record[0]], names = record[1], append(names, record[0])
+			}
+		}
+	}
+	leaderboards := []Leaderboard{}
+	for _, name := range names {
+		leaderboard, err := parseLeaderboardQuery(name, mapQueryByName[name])
+		if err != nil {
+			log.Fatalln("Invalid query of leaderboard '"+name+"':", err)
+		}
+		leaderboards = append(leaderboards, leaderboard)
+	}
+	return leaderboards
+}
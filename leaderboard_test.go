@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeLeaderboardQuery(t *testing.T) {
+	tokens, err := tokenizeLeaderboardQuery(`rank by (Wins*3 + Draws) / 2.5 where Team != "Man Utd", top 5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []queryToken{
+		{"word", "rank"}, {"word", "by"}, {"symbol", "("}, {"word", "Wins"}, {"symbol", "*"}, {"number", "3"},
+		{"symbol", "+"}, {"word", "Draws"}, {"symbol", ")"}, {"symbol", "/"}, {"number", "2.5"}, {"word", "where"},
+		{"word", "Team"}, {"symbol", "!="}, {"string", "Man Utd"}, {"symbol", ","}, {"word", "top"}, {"number", "5"},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("got %v\nwant %v", tokens, want)
+	}
+	for _, query := range []string{"rank by Points; top 5", `where Team = "Arsenal`, "rank by Points % 2"} {
+		if _, err := tokenizeLeaderboardQuery(query); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestParseLeaderboardQueryRejectsInvalidQueries(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"show Team, Points",
+		"rank Points",
+		"rank by Goals",
+		"rank by Team",
+		"rank by (Points + Wins",
+		"rank by Points where Points",
+		"rank by Points where Team > \"Arsenal\"",
+		"rank by Points where Team = Arsenal",
+		"rank by Points top 0",
+		"rank by Points top 2.5",
+		"rank by Points show Team, top",
+		"rank by Points limit 5",
+	} {
+		if _, err := parseLeaderboardQuery("Test", query); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestParseLeaderboardQuery(t *testing.T) {
+	leaderboard, err := parseLeaderboardQuery("Test", "RANK BY Wins * 3 + Draws asc, where GamesPlayed >= 2 and Team != \"Everton\", show Team, Points, Wins top 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaderboard.RankBy != "Wins * 3 + Draws" || !leaderboard.Ascending || leaderboard.Top != 2 || len(leaderboard.conditions) != 2 {
+		t.Errorf("got %+v", leaderboard)
+	}
+	if want := []string{"Team", "Points", "Wins"}; !reflect.DeepEqual(leaderboard.Fields, want) {
+		t.Errorf("got fields %v, want %v", leaderboard.Fields, want)
+	}
+	// Fields default to the team, ranking expression and games played
+	if leaderboard, _ := parseLeaderboardQuery("Test", "rank by PPG"); !reflect.DeepEqual(leaderboard.Fields, []string{"Team", "PPG", "GamesPlayed"}) {
+		t.Errorf("got default fields %v", leaderboard.Fields)
+	}
+}
+
+func TestGetLeaderboardRecords(t *testing.T) {
+	sliceAbsStats := []StatsAbs{
+		{Team: "Arsenal", GamesPlayed: 3, Points: 7, Wins: 2, Draws: 1},
+		{Team: "Chelsea", GamesPlayed: 3, Points: 3, Wins: 1, Losses: 2},
+		{Team: "Everton", GamesPlayed: 3, Points: 9, Wins: 3},
+		{Team: "Fulham", GamesPlayed: 1, Points: 1, Draws: 1},
+	}
+	testCases := []struct {
+		query string
+		want  [][]string
+	}{
+		{"rank by Points where GamesPlayed >= 2", [][]string{
+			{"Rank", "Team", "Points", "GamesPlayed"}, {"1", "Everton", "9", "3"}, {"2", "Arsenal", "7", "3"}, {"3", "Chelsea", "3", "3"},
+		}},
+		{"rank by Wins - Draws asc, show Team, Wins, top 2", [][]string{
+			{"Rank", "Team", "Wins"}, {"1", "Fulham", "0"}, {"2", "Arsenal", "2"},
+		}},
+		{"rank by PPG where Team = \"Chelsea\"", [][]string{
+			{"Rank", "Team", "PPG", "GamesPlayed"}, {"1", "Chelsea", "1", "3"},
+		}},
+	}
+	for _, testCase := range testCases {
+		leaderboard, err := parseLeaderboardQuery("Test", testCase.query)
+		if err != nil {
+			t.Fatalf("%q: %v", testCase.query, err)
+		}
+		if got := getLeaderboardRecords(leaderboard, sliceAbsStats, PipelineOptions{}); !reflect.DeepEqual(got, testCase.want) {
+			t.Errorf("%q: got %v\nwant %v", testCase.query, got, testCase.want)
+		}
+	}
+}
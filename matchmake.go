@@ -0,0 +1,218 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// Struct to store a suggested 2v2 game of a session, along with its predicted balance
+type SuggestedGame struct {
+	Game               int
+	HomeTeam           string
+	AwayTeam           string
+	HomeRating         float64 // Average Elo rating of the home team's members
+	AwayRating         float64 // Average Elo rating of the away team's members
+	RatingGap          float64
+	HomeWinProbability float64 // Expected score of the home team, as per Elo ratings
+	RepeatPartnerships int     // Number of both teams' partnerships that were recently played
+	SittingOut         string  // Individuals present but not playing this game
+}
+
+/*
+Method that gets slice of stringified elements of `SuggestedGame` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `SuggestedGame` struct to CSV file.
+*/
+func (obj SuggestedGame) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Game))
+	values = append(values, obj.HomeTeam)
+	values = append(values, obj.AwayTeam)
+	values = append(values, fmt.Sprintf("%g", obj.HomeRating))
+	values = append(values, fmt.Sprintf("%g", obj.AwayRating))
+	values = append(values, fmt.Sprintf("%g", obj.RatingGap))
+	values = append(values, fmt.Sprintf("%g", obj.HomeWinProbability))
+	values = append(values, strconv.Itoa(obj.RepeatPartnerships))
+	values = append(values, obj.SittingOut)
+	return values
+}
+
+// Gets number of times both individuals were partners in the given 2v2 `RawData` records (usually the latest ones)
+func getPartnershipCount(records []RawData, individualA string, individualB string) int {
+	count := 0
+	for _, record := range records {
+		for _, team := range []string{record.HomeTeam, record.AwayTeam} {
+			if individualInTeam(individualA, team) && individualInTeam(individualB, team) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+/*
+Gets the best split of 4 individuals into two 2v2 teams, minimising the rating gap between both teams plus
+`partnershipPenalty` rating points for every partnership found in `recentRecords`.
+Returns both teams, the number of repeated partnerships, and the cost of the split.
+*/
+func getBestSplit(quartet [4]string, ratings map[string]float64, recentRecords []RawData, partnershipPenalty float64) ([2]string, [2]string, int, float64) {
+	var bestHome, bestAway [2]string
+	bestRepeats, bestCost := 0, math.Inf(1)
+	// The first individual partners each of the other three in turn
+	for partner := 1; partner <= 3; partner++ {
+		home := [2]string{quartet[0], quartet[partner]}
+		away := [2]string{}
+		idx := 0
+		for i := 1; i <= 3; i++ {
+			if i != partner {
+				away[idx] = quartet[i]
+				idx++
+			}
+		}
+		gap := math.Abs(getTeamRatingFromMembers(ratings, home[:]) - getTeamRatingFromMembers(ratings, away[:]))
+		repeats := getPartnershipCount(recentRecords, home[0], home[1]) + getPartnershipCount(recentRecords, away[0], away[1])
+		cost := gap + partnershipPenalty*float64(repeats)
+		if cost < bestCost {
+			bestHome, bestAway, bestRepeats, bestCost = home, away, repeats, cost
+		}
+	}
+	return bestHome, bestAway, bestRepeats, bestCost
+}
+
+/*
+Gets a suggested schedule of `numGames` 2v2 games between the individuals present. Every game is played by the
+individuals who have played the fewest games of the session so far, and among those, the group of 4 and its split
+into teams is chosen to minimise the predicted skill gap plus a penalty for repeating partnerships.
+Partnerships of the latest `recentRecords` and of games already suggested count as repeated.
+*/
+func getSuggestedGames(individuals []string, ratings map[string]float64, recentRecords []RawData, numGames int, partnershipPenalty float64) []SuggestedGame {
+	sliceSuggestedGames := []SuggestedGame{}
+	mapGamesByIndividual := map[string]int{}
+	history := append([]RawData{}, recentRecords...)
+	for game := 1; game <= numGames; game++ {
+		// Individuals having played the fewest games so far go first, and fill up the quartet
+		candidates := append([]string{}, individuals...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return mapGamesByIndividual[candidates[i]] < mapGamesByIndividual[candidates[j]]
+		})
+		fewestGames := mapGamesByIndividual[candidates[3]]
+		mustPlay, mayPlay := []string{}, []string{}
+		for _, candidate := range candidates {
+			if mapGamesByIndividual[candidate] < fewestGames {
+				mustPlay = append(mustPlay, candidate)
+			} else if mapGamesByIndividual[candidate] == fewestGames {
+				mayPlay = append(mayPlay, candidate)
+			}
+		}
+		var bestHome, bestAway [2]string
+		bestRepeats, bestCost := 0, math.Inf(1)
+		for _, chosen := range getCombinations(mayPlay, 4-len(mustPlay)) {
+			quartet := [4]string{}
+			copy(quartet[:], append(append([]string{}, mustPlay...), chosen...))
+			home, away, repeats, cost := getBestSplit(quartet, ratings, history, partnershipPenalty)
+			if cost < bestCost {
+				bestHome, bestAway, bestRepeats, bestCost = home, away, repeats, cost
+			}
+		}
+		homeRating := getTeamRatingFromMembers(ratings, bestHome[:])
+		awayRating := getTeamRatingFromMembers(ratings, bestAway[:])
+		tempObj := SuggestedGame{
+			Game:               game,
+			HomeTeam:           get2v2TeamName(bestHome[0], bestHome[1]),
+			AwayTeam:           get2v2TeamName(bestAway[0], bestAway[1]),
+			HomeRating:         round(homeRating, 1),
+			AwayRating:         round(awayRating, 1),
+			RatingGap:          round(math.Abs(homeRating-awayRating), 1),
+			HomeWinProbability: round(getEloExpectedScore(homeRating, awayRating), 3),
+			RepeatPartnerships: bestRepeats,
+		}
+		sittingOut := []string{}
+		for _, individual := range individuals {
+			if individualInTeam(individual, tempObj.HomeTeam) || individualInTeam(individual, tempObj.AwayTeam) {
+				mapGamesByIndividual[individual]++
+			} else {
+				sittingOut = append(sittingOut, individual)
+			}
+		}
+		tempObj.SittingOut = strings.Join(sittingOut, " ")
+		sliceSuggestedGames = append(sliceSuggestedGames, tempObj)
+		history = append(history, RawData{HomeTeam: tempObj.HomeTeam, AwayTeam: tempObj.AwayTeam})
+	}
+	return sliceSuggestedGames
+}
+
+// Gets all combinations of `k` elements from the given slice, keeping the order of elements
+func getCombinations(elements []string, k int) [][]string {
+	if k == 0 {
+		return [][]string{{}}
+	}
+	combinations := [][]string{}
+	for idx := 0; idx+k <= len(elements); idx++ {
+		for _, rest := range getCombinations(elements[idx+1:], k-1) {
+			combinations = append(combinations, append([]string{elements[idx]}, rest...))
+		}
+	}
+	return combinations
+}
+
+// Saves slice having objects of `SuggestedGame` struct to CSV file
+func saveSuggestedGamesToCsv(sliceData []SuggestedGame, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&SuggestedGame{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+/*
+Command that suggests a schedule of balanced 2v2 games for the individuals present, rated by Elo over all 2v2 data
+files (in order of filename). Individuals without any 2v2 games get the initial rating.
+*/
+func runMatchmakeCommand(args []string) {
+	flagSet := flag.NewFlagSet("matchmake", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	individuals := flagSet.String("individuals", "", "Comma-separated unique-names of individuals present")
+	numGames := flagSet.Int("games", 6, "Number of games to suggest")
+	numRecentGames := flagSet.Int("recent", 10, "Number of latest 2v2 games whose partnerships shouldn't be repeated")
+	partnershipPenalty := flagSet.Float64("partnership-penalty", 50, "Rating points added to the gap of a split for each repeated partnership")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+
+	sliceIndividuals := splitNames(*individuals)
+	checkUniqueNames(sliceIndividuals)
+	if len(sliceIndividuals) < 4 {
+		log.Fatalln("Matchmaking needs at least 4 individuals. Use -individuals")
+	}
+	filenames := getListOfDataFilenames()
+	sort.Strings(filenames)
+	records2v2 := []RawData{}
+	for _, filename := range filenames {
+		if !filenameContains2v2(filename) {
+			continue
+		}
+		rawRecords := loadRawRecords(pathDataFolder+"/"+filename, *options)
+		if isValid2v2Naming(rawRecords) {
+			records2v2 = append(records2v2, rawRecords...)
+		}
+	}
+	ratings := getIndividualEloRatings(records2v2, options.ScoringRules)
+	recentRecords := records2v2
+	if len(recentRecords) > *numRecentGames {
+		recentRecords = recentRecords[len(recentRecords)-*numRecentGames:]
+	}
+	sliceSuggestedGames := getSuggestedGames(sliceIndividuals, ratings, recentRecords, *numGames, *partnershipPenalty)
+	for _, obj := range sliceSuggestedGames {
+		fmt.Println("Game " + strconv.Itoa(obj.Game) + ": " + obj.HomeTeam + " vs " + obj.AwayTeam + " (rating gap " + strconv.FormatFloat(obj.RatingGap, 'f', 1, 64) + ")")
+	}
+	saveSuggestedGamesToCsv(sliceSuggestedGames, pathResultsFolder+"/Matchmaking.csv")
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// Constants - Names of per-side match statistics
+const (
+	statShots         = "Shots"
+	statShotsOnTarget = "ShotsOnTarget"
+	statXG            = "xG"
+	statCorners       = "Corners"
+	statFouls         = "Fouls"
+	statYellowCards   = "YellowCards"
+	statRedCards      = "RedCards"
+)
+
+// Names of all per-side match statistics, in the order they're listed in messages
+var statNames = []string{statShots, statShotsOnTarget, statXG, statCorners, statFouls, statYellowCards, statRedCards}
+
+// Constants - Sides of a match, used in mapping of statistic columns
+const (
+	sideHome = "home"
+	sideAway = "away"
+)
+
+// Struct to store which side and statistic a column of a data file holds
+type StatColumn struct {
+	Side string
+	Stat string
+}
+
+// Default mapping of (lower-cased) column names to statistics, as used by football-data.co.uk files
+var defaultStatColumns = map[string]StatColumn{
+	"hs":  {sideHome, statShots},
+	"as":  {sideAway, statShots},
+	"hst": {sideHome, statShotsOnTarget},
+	"ast": {sideAway, statShotsOnTarget},
+	"hxg": {sideHome, statXG},
+	"axg": {sideAway, statXG},
+	"hc":  {sideHome, statCorners},
+	"ac":  {sideAway, statCorners},
+	"hf":  {sideHome, statFouls},
+	"af":  {sideAway, statFouls},
+	"hy":  {sideHome, statYellowCards},
+	"ay":  {sideAway, statYellowCards},
+	"hr":  {sideHome, statRedCards},
+	"ar":  {sideAway, statRedCards},
+}
+
+// Struct to store absolute match statistics (only games having statistics are considered)
+type MatchStatsAbs struct {
+	Rank                 int
+	Team                 string
+	GamesPlayed          int
+	GoalsScored          int
+	GoalsAllowed         int
+	Shots                float64
+	ShotsOnTarget        float64
+	ShotsAgainst         float64
+	ShotsOnTargetAgainst float64
+	XGFor                float64
+	XGAgainst            float64
+	XGDifference         float64
+	Corners              float64
+	Fouls                float64
+	YellowCards          float64
+	RedCards             float64
+	ExpectedPoints       float64 // Points expected from xG of both sides, assuming Poisson distributed goals
+}
+
+// Struct to store normalized match statistics i.e; MatchStatsAbs / GamesPlayed (and conversion rates)
+type MatchStatsNorm struct {
+	Rank              int
+	Team              string
+	GamesPlayed       int
+	ShotsPG           float64
+	ShotsAgainstPG    float64
+	OnTargetPct       float64 // Shots on target / Shots
+	ShotConversionPct float64 // Goals / Shots
+	XGForPG           float64
+	XGAgainstPG       float64
+	XGDifferencePG    float64
+	GoalsMinusXG      float64 // Goals scored in excess of xG i.e; finishing over/under-performance
+	CornersPG         float64
+	FoulsPG           float64
+	CardsPG           float64 // Yellow + red cards per game
+	ExpectedPPG       float64
+}
+
+/*
+Method that gets slice of stringified elements of `MatchStatsAbs` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `MatchStatsAbs` struct to CSV file.
+*/
+func (obj MatchStatsAbs) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, strconv.Itoa(obj.GoalsScored))
+	values = append(values, strconv.Itoa(obj.GoalsAllowed))
+	for _, value := range []float64{
+		obj.Shots, obj.ShotsOnTarget, obj.ShotsAgainst, obj.ShotsOnTargetAgainst,
+		obj.XGFor, obj.XGAgainst, obj.XGDifference,
+		obj.Corners, obj.Fouls, obj.YellowCards, obj.RedCards, obj.ExpectedPoints,
+	} {
+		values = append(values, fmt.Sprintf("%g", value))
+	}
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `MatchStatsNorm` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `MatchStatsNorm` struct to CSV file.
+*/
+func (obj MatchStatsNorm) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	for _, value := range []float64{
+		obj.ShotsPG, obj.ShotsAgainstPG, obj.OnTargetPct, obj.ShotConversionPct,
+		obj.XGForPG, obj.XGAgainstPG, obj.XGDifferencePG, obj.GoalsMinusXG,
+		obj.CornersPG, obj.FoulsPG, obj.CardsPG, obj.ExpectedPPG,
+	} {
+		values = append(values, fmt.Sprintf("%g", value))
+	}
+	return values
+}
+
+/*
+Reads CSV file having columns "Column, Side, Stat" in that order, where Side is "home" or "away".
+Returns the default mapping of statistic columns, extended/overridden by the mapping read from the file.
+*/
+func readStatColumns(filepath string) map[string]StatColumn {
+	mapStatColumnByName := map[string]StatColumn{}
+	for name, statColumn := range defaultStatColumns {
+		mapStatColumnByName[name] = statColumn
+	}
+	if filepath == "" {
+		return mapStatColumnByName
+	}
+	csvfile, err := os.Open(filepath)
+	if err != nil {
+		log.Fatalln("Couldn't open the stat columns file", err)
+	}
+	defer csvfile.Close()
+	r := csv.NewReader(csvfile)
+	lineCount := 0
+	for {
+		lineCount++
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if lineCount != 1 {
+			if len(record) < 3 {
+				log.Fatalln("Expected the columns 'Column, Side, Stat' in stat columns file at line " + strconv.Itoa(lineCount))
+			}
+			side := strings.ToLower(strings.TrimSpace(record[1]))
+			if side != sideHome && side != sideAway {
+				log.Fatalln("Side must be 'home' or 'away' in stat columns file at line " + strconv.Itoa(lineCount))
+			}
+			stat, ok := getStatName(record[2])
+			if !ok {
+				log.Fatalln("Unknown stat '" + strings.TrimSpace(record[2]) + "' in stat columns file at line " + strconv.Itoa(lineCount) + ". Expected one of: " + strings.Join(statNames, ", "))
+			}
+			mapStatColumnByName[strings.ToLower(strings.TrimSpace(record[0]))] = StatColumn{Side: side, Stat: stat}
+		}
+	}
+	return mapStatColumnByName
+}
+
+// Gets the name of the per-side match statistic matching `name` (case-insensitive). Returns false if there's no such statistic
+func getStatName(name string) (string, bool) {
+	for _, stat := range statNames {
+		if strings.EqualFold(stat, strings.TrimSpace(name)) {
+			return stat, true
+		}
+	}
+	return "", false
+}
+
+/*
+Fills per-side statistics of a `RawData` record from a row of a data file, using the mapping of statistic columns.
+Empty cells are skipped, so the maps only hold statistics that are available for the match.
+*/
+func fillMatchStatistics(rawRecord *RawData, row []string, mapColumnIndexByName map[string]int, mapStatColumnByName map[string]StatColumn, lineCount int) error {
+	for name, idx := range mapColumnIndexByName {
+		statColumn, ok := mapStatColumnByName[name]
+		if !ok || strings.TrimSpace(row[idx]) == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(row[idx]), 64)
+		if err != nil {
+			return errors.New("Error while converting column '" + name + "' to number at line " + strconv.Itoa(lineCount) + " " + err.Error())
+		}
+		if statColumn.Side == sideHome {
+			if rawRecord.HomeStats == nil {
+				rawRecord.HomeStats = map[string]float64{}
+			}
+			rawRecord.HomeStats[statColumn.Stat] = value
+		} else {
+			if rawRecord.AwayStats == nil {
+				rawRecord.AwayStats = map[string]float64{}
+			}
+			rawRecord.AwayStats[statColumn.Stat] = value
+		}
+	}
+	return nil
+}
+
+// Returns true if any of the `RawData` records has per-side statistics
+func hasMatchStatistics(records []RawData) bool {
+	for _, record := range records {
+		if len(record.HomeStats) > 0 || len(record.AwayStats) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Gets slice of absolute match statistics from `RawData` records (only records having statistics are considered).
+Returns slice wherein each element of the slice is an object of the struct `MatchStatsAbs`
+*/
+func getMatchStatsAbs(records []RawData, rules ScoringRules) []MatchStatsAbs {
+	sliceMatchStatsAbs := []MatchStatsAbs{}
+	for _, team := range getUniqueTeamNames(records) {
+		tempObj := MatchStatsAbs{Team: team}
+		expectedPoints := 0.0
+		for _, record := range records {
+			if len(record.HomeStats) == 0 && len(record.AwayStats) == 0 {
+				continue
+			}
+			var statsFor, statsAgainst map[string]float64
+			var goalsFor, goalsAgainst int
+			homeGoals, awayGoals := getScoreForTable(record, rules)
+			if record.HomeTeam == team {
+				statsFor, statsAgainst = record.HomeStats, record.AwayStats
+				goalsFor, goalsAgainst = homeGoals, awayGoals
+			} else if record.AwayTeam == team {
+				statsFor, statsAgainst = record.AwayStats, record.HomeStats
+				goalsFor, goalsAgainst = awayGoals, homeGoals
+			} else {
+				continue
+			}
+			tempObj.GamesPlayed++
+			tempObj.GoalsScored += goalsFor
+			tempObj.GoalsAllowed += goalsAgainst
+			tempObj.Shots += statsFor[statShots]
+			tempObj.ShotsOnTarget += statsFor[statShotsOnTarget]
+			tempObj.ShotsAgainst += statsAgainst[statShots]
+			tempObj.ShotsOnTargetAgainst += statsAgainst[statShotsOnTarget]
+			tempObj.XGFor += statsFor[statXG]
+			tempObj.XGAgainst += statsAgainst[statXG]
+			tempObj.Corners += statsFor[statCorners]
+			tempObj.Fouls += statsFor[statFouls]
+			tempObj.YellowCards += statsFor[statYellowCards]
+			tempObj.RedCards += statsFor[statRedCards]
+			_, xgForOk := statsFor[statXG]
+			_, xgAgainstOk := statsAgainst[statXG]
+			if xgForOk && xgAgainstOk {
+				win, draw, _ := getMatchOutcomeProbabilities(statsFor[statXG], statsAgainst[statXG])
+				expectedPoints += 3*win + draw
+			}
+		}
+		tempObj.XGFor = round(tempObj.XGFor, 2)
+		tempObj.XGAgainst = round(tempObj.XGAgainst, 2)
+		tempObj.XGDifference = round(tempObj.XGFor-tempObj.XGAgainst, 2)
+		tempObj.ExpectedPoints = round(expectedPoints, 2)
+		if tempObj.GamesPlayed > 0 {
+			sliceMatchStatsAbs = append(sliceMatchStatsAbs, tempObj)
+		}
+	}
+	return sliceMatchStatsAbs
+}
+
+/*
+Gets slice of normalized match statistics from slice of absolute match statistics.
+Returns slice wherein each element of the slice is an object of the struct `MatchStatsNorm`
+*/
+func getMatchStatsNorm(sliceMatchStatsAbs []MatchStatsAbs) []MatchStatsNorm {
+	hundred := 100.0
+	sliceMatchStatsNorm := []MatchStatsNorm{}
+	for _, obj := range sliceMatchStatsAbs {
+		gamesPlayed := float64(obj.GamesPlayed)
+		onTargetPct, shotConversionPct := 0.0, 0.0
+		if obj.Shots > 0 {
+			onTargetPct = round(obj.ShotsOnTarget*hundred/obj.Shots, 2)
+			shotConversionPct = round(float64(obj.GoalsScored)*hundred/obj.Shots, 2)
+		}
+		tempObj := MatchStatsNorm{
+			Team:              obj.Team,
+			GamesPlayed:       obj.GamesPlayed,
+			ShotsPG:           round(obj.Shots/gamesPlayed, 3),
+			ShotsAgainstPG:    round(obj.ShotsAgainst/gamesPlayed, 3),
+			OnTargetPct:       onTargetPct,
+			ShotConversionPct: shotConversionPct,
+			XGForPG:           round(obj.XGFor/gamesPlayed, 3),
+			XGAgainstPG:       round(obj.XGAgainst/gamesPlayed, 3),
+			XGDifferencePG:    round(obj.XGDifference/gamesPlayed, 3),
+			GoalsMinusXG:      round(float64(obj.GoalsScored)-obj.XGFor, 2),
+			CornersPG:         round(obj.Corners/gamesPlayed, 3),
+			FoulsPG:           round(obj.Fouls/gamesPlayed, 3),
+			CardsPG:           round((obj.YellowCards+obj.RedCards)/gamesPlayed, 3),
+			ExpectedPPG:       round(obj.ExpectedPoints/gamesPlayed, 4),
+		}
+		sliceMatchStatsNorm = append(sliceMatchStatsNorm, tempObj)
+	}
+	return sliceMatchStatsNorm
+}
+
+// Sorts absolute match statistics based on xG difference (then shot difference), and attaches ranking
+func sortAndRankMatchStatsAbs(sliceMatchStatsAbs []MatchStatsAbs) []MatchStatsAbs {
+	sort.SliceStable(sliceMatchStatsAbs, func(i, j int) bool {
+		if sliceMatchStatsAbs[i].XGDifference != sliceMatchStatsAbs[j].XGDifference {
+			return sliceMatchStatsAbs[i].XGDifference > sliceMatchStatsAbs[j].XGDifference
+		}
+		return sliceMatchStatsAbs[i].Shots-sliceMatchStatsAbs[i].ShotsAgainst > sliceMatchStatsAbs[j].Shots-sliceMatchStatsAbs[j].ShotsAgainst
+	})
+	for idx := range sliceMatchStatsAbs {
+		sliceMatchStatsAbs[idx].Rank = idx + 1
+	}
+	return sliceMatchStatsAbs
+}
+
+// Sorts normalized match statistics based on xG difference per game (then shot difference per game), and attaches ranking
+func sortAndRankMatchStatsNorm(sliceMatchStatsNorm []MatchStatsNorm) []MatchStatsNorm {
+	sort.SliceStable(sliceMatchStatsNorm, func(i, j int) bool {
+		if sliceMatchStatsNorm[i].XGDifferencePG != sliceMatchStatsNorm[j].XGDifferencePG {
+			return sliceMatchStatsNorm[i].XGDifferencePG > sliceMatchStatsNorm[j].XGDifferencePG
+		}
+		return sliceMatchStatsNorm[i].ShotsPG-sliceMatchStatsNorm[i].ShotsAgainstPG > sliceMatchStatsNorm[j].ShotsPG-sliceMatchStatsNorm[j].ShotsAgainstPG
+	})
+	for idx := range sliceMatchStatsNorm {
+		sliceMatchStatsNorm[idx].Rank = idx + 1
+	}
+	return sliceMatchStatsNorm
+}
+
+// Saves slice having objects of `MatchStatsAbs` struct to CSV file
+func saveMatchStatsAbsToCsv(sliceData []MatchStatsAbs, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&MatchStatsAbs{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `MatchStatsNorm` struct to CSV file
+func saveMatchStatsNormToCsv(sliceData []MatchStatsNorm, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&MatchStatsNorm{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
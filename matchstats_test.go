@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestGetStatName(t *testing.T) {
+	testCases := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{"ShotsOnTarget", statShotsOnTarget, true},
+		{" xg ", statXG, true},
+		{"YELLOWCARDS", statYellowCards, true},
+		{"ShotOnTarget", "", false},
+		{"", "", false},
+	}
+	for _, testCase := range testCases {
+		if got, ok := getStatName(testCase.name); got != testCase.want || ok != testCase.wantOk {
+			t.Errorf("%q: got %q (%v), want %q (%v)", testCase.name, got, ok, testCase.want, testCase.wantOk)
+		}
+	}
+}
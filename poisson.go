@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+)
+
+// Goals per side up to which Poisson probabilities are summed. Higher scores are negligibly likely
+const poissonMaxGoals = 15
+
+// Gets probability of exactly `k` events for a Poisson distribution having mean `lambda`
+func getPoissonProbability(k int, lambda float64) float64 {
+	if lambda <= 0 {
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	logProbability := float64(k)*math.Log(lambda) - lambda
+	for i := 2; i <= k; i++ {
+		logProbability -= math.Log(float64(i))
+	}
+	return math.Exp(logProbability)
+}
+
+/*
+Gets probabilities of winning, drawing and losing a match, assuming goals for and against are independent
+Poisson variables having means `lambdaFor` and `lambdaAgainst`
+*/
+func getMatchOutcomeProbabilities(lambdaFor float64, lambdaAgainst float64) (float64, float64, float64) {
+	win, draw, loss := 0.0, 0.0, 0.0
+	for goalsFor := 0; goalsFor <= poissonMaxGoals; goalsFor++ {
+		probabilityFor := getPoissonProbability(goalsFor, lambdaFor)
+		for goalsAgainst := 0; goalsAgainst <= poissonMaxGoals; goalsAgainst++ {
+			probability := probabilityFor * getPoissonProbability(goalsAgainst, lambdaAgainst)
+			if goalsFor > goalsAgainst {
+				win += probability
+			} else if goalsFor == goalsAgainst {
+				draw += probability
+			} else {
+				loss += probability
+			}
+		}
+	}
+	return win, draw, loss
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Constants - Search range and precision used while fitting the Pythagorean exponent
+const (
+	pythagoreanExponentMin       = 0.5
+	pythagoreanExponentMax       = 5.0
+	pythagoreanExponentTolerance = 1e-6
+)
+
+// Struct to store expected points (from Pythagorean expectation) and luck i.e; actual minus expected points
+type Luck struct {
+	Rank           int
+	Team           string
+	GamesPlayed    int
+	Points         int
+	PythagoreanPct float64 // GS^x / (GS^x + GA^x) expressed as a percentage, where x is the exponent
+	ExpectedPoints float64
+	Luck           float64 // Points - ExpectedPoints. Positive means over-performing, negative means under-performing
+}
+
+/*
+Method that gets slice of stringified elements of `Luck` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `Luck` struct to CSV file.
+*/
+func (obj Luck) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, strconv.Itoa(obj.Points))
+	values = append(values, fmt.Sprintf("%g", obj.PythagoreanPct))
+	values = append(values, fmt.Sprintf("%g", obj.ExpectedPoints))
+	values = append(values, fmt.Sprintf("%g", obj.Luck))
+	return values
+}
+
+// Gets Pythagorean expectation (between 0 and 1) from goals scored and goals allowed
+func getPythagoreanExpectation(goalsScored int, goalsAllowed int, exponent float64) float64 {
+	if goalsScored == 0 && goalsAllowed == 0 {
+		return 0.5
+	}
+	gsPowered := math.Pow(float64(goalsScored), exponent)
+	gaPowered := math.Pow(float64(goalsAllowed), exponent)
+	return gsPowered / (gsPowered + gaPowered)
+}
+
+/*
+Gets expected points of a team having the given Pythagorean expectation.
+Since a match hands out between 2 (draw) and 3 (win) points in total, expected points are the team's share of the
+league-average points per match i.e; GamesPlayed * PythagoreanExpectation * (2 * LeaguePPG).
+This way, a team with equal goals scored and allowed is expected to get the league-average PPG.
+*/
+func getExpectedPoints(gamesPlayed int, pythagoreanExpectation float64, leaguePpg float64) float64 {
+	return float64(gamesPlayed) * pythagoreanExpectation * 2 * leaguePpg
+}
+
+// Gets sum of squared differences between actual and expected points, for the given exponent
+func getPythagoreanSquaredError(sliceAbsStats []StatsAbs, exponent float64) float64 {
+	leaguePpg := getLeaguePpg(sliceAbsStats)
+	squaredError := 0.0
+	for _, obj := range sliceAbsStats {
+		expectation := getPythagoreanExpectation(obj.GoalsScored, obj.GoalsAllowed, exponent)
+		diff := float64(obj.Points) - getExpectedPoints(obj.GamesPlayed, expectation, leaguePpg)
+		squaredError += diff * diff
+	}
+	return squaredError
+}
+
+/*
+Fits the Pythagorean exponent to the given absolute stats, by minimizing the squared error between actual and expected points.
+Uses golden-section search over [pythagoreanExponentMin, pythagoreanExponentMax].
+*/
+func fitPythagoreanExponent(sliceAbsStats []StatsAbs) float64 {
+	invPhi := (math.Sqrt(5) - 1) / 2
+	low, high := pythagoreanExponentMin, pythagoreanExponentMax
+	x1 := high - invPhi*(high-low)
+	x2 := low + invPhi*(high-low)
+	err1 := getPythagoreanSquaredError(sliceAbsStats, x1)
+	err2 := getPythagoreanSquaredError(sliceAbsStats, x2)
+	for high-low > pythagoreanExponentTolerance {
+		if err1 < err2 {
+			high, x2, err2 = x2, x1, err1
+			x1 = high - invPhi*(high-low)
+			err1 = getPythagoreanSquaredError(sliceAbsStats, x1)
+		} else {
+			low, x1, err1 = x1, x2, err2
+			x2 = low + invPhi*(high-low)
+			err2 = getPythagoreanSquaredError(sliceAbsStats, x2)
+		}
+	}
+	return (low + high) / 2
+}
+
+/*
+Gets slice of luck analysis from slice of absolute stats, using the given Pythagorean exponent.
+Returns slice wherein each element of the slice is an object of the struct `Luck`
+*/
+func getLuck(sliceAbsStats []StatsAbs, exponent float64) []Luck {
+	leaguePpg := getLeaguePpg(sliceAbsStats)
+	sliceLuck := []Luck{}
+	for _, obj := range sliceAbsStats {
+		expectation := getPythagoreanExpectation(obj.GoalsScored, obj.GoalsAllowed, exponent)
+		expectedPoints := getExpectedPoints(obj.GamesPlayed, expectation, leaguePpg)
+		tempObj := Luck{
+			Team:           obj.Team,
+			GamesPlayed:    obj.GamesPlayed,
+			Points:         obj.Points,
+			PythagoreanPct: round(expectation*100, 2),
+			ExpectedPoints: round(expectedPoints, 2),
+			Luck:           round(float64(obj.Points)-expectedPoints, 2),
+		}
+		sliceLuck = append(sliceLuck, tempObj)
+	}
+	return sliceLuck
+}
+
+// Sorts luck analysis from most over-performing to most under-performing, and attaches ranking
+func sortAndRankLuck(sliceLuck []Luck) []Luck {
+	sort.SliceStable(sliceLuck, func(i, j int) bool {
+		return sliceLuck[i].Luck > sliceLuck[j].Luck
+	})
+	for idx := range sliceLuck {
+		sliceLuck[idx].Rank = idx + 1
+	}
+	return sliceLuck
+}
+
+/*
+Gets ranked luck analysis from absolute stats. Uses the exponent given in `PipelineOptions`, or fits one to the data
+if no exponent is given. Returns the exponent used along with the analysis.
+*/
+func getRankedLuck(sliceAbsStats []StatsAbs, options PipelineOptions) ([]Luck, float64) {
+	exponent := options.PythagoreanExponent
+	if exponent <= 0 {
+		exponent = fitPythagoreanExponent(sliceAbsStats)
+	}
+	sliceLuck := getLuck(sliceAbsStats, exponent)
+	return sortAndRankLuck(sliceLuck), exponent
+}
+
+// Saves slice having objects of `Luck` struct to CSV file
+func saveLuckToCsv(sliceData []Luck, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&Luck{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
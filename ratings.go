@@ -0,0 +1,109 @@
+package main
+
+import "math"
+
+// Constants - Parameters of the Elo rating system
+const (
+	eloInitialRating = 1500.0 // Rating given to a team before its first game
+	eloKFactor       = 20.0   // Maximum rating change per game (before goal difference multiplier)
+	eloScale         = 400.0  // Rating difference at which the stronger side is expected to score 10 times more
+)
+
+// Gets expected score (between 0 and 1) of a side rated `rating` against a side rated `opponentRating`
+func getEloExpectedScore(rating float64, opponentRating float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponentRating-rating)/eloScale))
+}
+
+// Gets multiplier of rating change based on goal margin, so that big wins move ratings more than narrow ones
+func getEloGoalDiffMultiplier(goalDiff int) float64 {
+	margin := int(math.Abs(float64(goalDiff)))
+	if margin <= 1 {
+		return 1
+	} else if margin == 2 {
+		return 1.5
+	}
+	return (11 + float64(margin)) / 8
+}
+
+// Gets actual score (1 for win, 0.5 for draw, 0 for loss) of the home side of a match, given its home and away goals
+func getHomeResultScore(homeGoals int, awayGoals int) float64 {
+	if homeGoals > awayGoals {
+		return 1
+	} else if homeGoals == awayGoals {
+		return 0.5
+	}
+	return 0
+}
+
+// Gets rating of a team from the map of ratings, defaulting to `eloInitialRating` for unrated teams
+func getRating(ratings map[string]float64, team string) float64 {
+	rating, ok := ratings[team]
+	if !ok {
+		return eloInitialRating
+	}
+	return rating
+}
+
+// Updates Elo ratings of both teams (in place) with the result of the given match
+func updateEloRatings(ratings map[string]float64, record RawData, rules ScoringRules) {
+	homeRating := getRating(ratings, record.HomeTeam)
+	awayRating := getRating(ratings, record.AwayTeam)
+	expectedHome := getEloExpectedScore(homeRating, awayRating)
+	homeGoals, awayGoals := getScoreForTable(record, rules)
+	delta := eloKFactor * getEloGoalDiffMultiplier(homeGoals-awayGoals) * (getHomeResultScore(homeGoals, awayGoals) - expectedHome)
+	ratings[record.HomeTeam] = homeRating + delta
+	ratings[record.AwayTeam] = awayRating - delta
+}
+
+/*
+Gets Elo ratings of teams after all `RawData` records have been played.
+NOTE: Assumes that the records are sorted in ascending order of time of occurence of matches.
+*/
+func getEloRatings(records []RawData, rules ScoringRules) map[string]float64 {
+	ratings := map[string]float64{}
+	for _, record := range records {
+		updateEloRatings(ratings, record, rules)
+	}
+	return ratings
+}
+
+/*
+Updates Elo ratings of individuals (in place) with the result of the given 2v2 match. The rating of a team is the
+average rating of its members, and each member's rating changes as much as the team's rating would.
+*/
+func updateIndividualEloRatings(ratings map[string]float64, record RawData, rules ScoringRules) {
+	homeMembers := individualNamePattern.FindAllString(record.HomeTeam, -1)
+	awayMembers := individualNamePattern.FindAllString(record.AwayTeam, -1)
+	homeRating := getTeamRatingFromMembers(ratings, homeMembers)
+	awayRating := getTeamRatingFromMembers(ratings, awayMembers)
+	expectedHome := getEloExpectedScore(homeRating, awayRating)
+	homeGoals, awayGoals := getScoreForTable(record, rules)
+	delta := eloKFactor * getEloGoalDiffMultiplier(homeGoals-awayGoals) * (getHomeResultScore(homeGoals, awayGoals) - expectedHome)
+	for _, member := range homeMembers {
+		ratings[member] = getRating(ratings, member) + delta
+	}
+	for _, member := range awayMembers {
+		ratings[member] = getRating(ratings, member) - delta
+	}
+}
+
+// Gets rating of a team as the average rating of its members
+func getTeamRatingFromMembers(ratings map[string]float64, members []string) float64 {
+	total := 0.0
+	for _, member := range members {
+		total += getRating(ratings, member)
+	}
+	return total / float64(len(members))
+}
+
+/*
+Gets Elo ratings of individuals after all 2v2 `RawData` records have been played.
+NOTE: Assumes that the records are sorted in ascending order of time of occurence of matches.
+*/
+func getIndividualEloRatings(records []RawData, rules ScoringRules) map[string]float64 {
+	ratings := map[string]float64{}
+	for _, record := range records {
+		updateIndividualEloRatings(ratings, record, rules)
+	}
+	return ratings
+}
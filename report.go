@@ -0,0 +1,545 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/fatih/structs"
+)
+
+// Number of latest games the form chart's rolling PPG is computed over
+const reportFormWindow = 5
+
+// Number of best/worst results and most-faced opponents in a report
+const reportTopResults = 5
+
+// Struct to store a match of a team (or individual) in its report, along with running totals
+type ReportMatch struct {
+	Number              int
+	Date                string
+	Venue               string // "Home" or "Away"
+	Partner             string // Only for individuals of 2v2 data files
+	Opponent            string
+	GoalsFor            int
+	GoalsAgainst        int
+	Result              string
+	Points              int
+	TotalPoints         int
+	TotalGoalDifference int
+	RunningPPG          float64
+	FormPPG             float64 // PPG in the latest `reportFormWindow` games, up to this match
+}
+
+// Struct to store stats of a team (or individual) in a subset of its matches i.e; home games, games against an opponent
+type ReportSplit struct {
+	Split        string
+	GamesPlayed  int
+	Wins         int
+	Draws        int
+	Losses       int
+	GoalsScored  int
+	GoalsAllowed int
+	Points       int
+	PPG          float64
+}
+
+// Struct to store the longest and current run of matches meeting a condition i.e; unbeaten, scoring
+type ReportStreak struct {
+	Streak  string
+	Longest int
+	Current int
+}
+
+// Struct to store a table of a report
+type ReportTable struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// Struct to store a report of a team (or individual), which is rendered as Markdown or HTML
+type TeamReport struct {
+	Title           string
+	Subtitle        string
+	Form            string // WLD of all matches, latest last
+	FormChart       string // Sparkline of `FormPPG` by match (for Markdown)
+	FormChartPoints string // Points of a polyline of `FormPPG` by match (for HTML)
+	Tables          []ReportTable
+}
+
+/*
+Method that gets slice of stringified elements of `ReportMatch` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in rendering data of `ReportMatch` struct as rows of report tables.
+*/
+func (obj ReportMatch) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Number))
+	values = append(values, obj.Date)
+	values = append(values, obj.Venue)
+	values = append(values, obj.Partner)
+	values = append(values, obj.Opponent)
+	values = append(values, strconv.Itoa(obj.GoalsFor))
+	values = append(values, strconv.Itoa(obj.GoalsAgainst))
+	values = append(values, obj.Result)
+	values = append(values, strconv.Itoa(obj.Points))
+	values = append(values, strconv.Itoa(obj.TotalPoints))
+	values = append(values, strconv.Itoa(obj.TotalGoalDifference))
+	values = append(values, fmt.Sprintf("%g", obj.RunningPPG))
+	values = append(values, fmt.Sprintf("%g", obj.FormPPG))
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `ReportSplit` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in rendering data of `ReportSplit` struct as rows of report tables.
+*/
+func (obj ReportSplit) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Split)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, strconv.Itoa(obj.Wins))
+	values = append(values, strconv.Itoa(obj.Draws))
+	values = append(values, strconv.Itoa(obj.Losses))
+	values = append(values, strconv.Itoa(obj.GoalsScored))
+	values = append(values, strconv.Itoa(obj.GoalsAllowed))
+	values = append(values, strconv.Itoa(obj.Points))
+	values = append(values, fmt.Sprintf("%g", obj.PPG))
+	return values
+}
+
+/*
+Method that gets slice of stringified elements of `ReportStreak` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in rendering data of `ReportStreak` struct as rows of report tables.
+*/
+func (obj ReportStreak) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Streak)
+	values = append(values, strconv.Itoa(obj.Longest))
+	values = append(values, strconv.Itoa(obj.Current))
+	return values
+}
+
+/*
+Gets whether the team (or individual, if `solo` is true) played a match at home, along with their partner (for
+individuals) and opponent
+*/
+func getMatchSide(record RawData, team string, solo bool) (bool, string, string) {
+	if !solo {
+		if record.HomeTeam == team {
+			return true, "", record.AwayTeam
+		}
+		return false, "", record.HomeTeam
+	}
+	isHome := individualInTeam(team, record.HomeTeam)
+	ownTeam, opponent := record.HomeTeam, record.AwayTeam
+	if !isHome {
+		ownTeam, opponent = record.AwayTeam, record.HomeTeam
+	}
+	partner := ""
+	for _, individual := range regexp.MustCompile(`[A-Z][^A-Z]*`).FindAllString(ownTeam, -1) {
+		if individual != team {
+			partner = individual
+		}
+	}
+	return isHome, partner, opponent
+}
+
+// Gets matches of a team (or individual, if `solo` is true) from `RawData` records, along with running totals
+func getReportMatches(records []RawData, team string, solo bool, rules ScoringRules) []ReportMatch {
+	sliceReportMatches := []ReportMatch{}
+	totalPoints, totalGoalDifference, formPoints := 0, 0, []int{}
+	for idx, record := range filterByTeams(records, []string{team}, solo) {
+		isHome, partner, opponent := getMatchSide(record, team, solo)
+		goalsFor, goalsAgainst := getScoreForTable(record, rules)
+		venue := "Home"
+		if !isHome {
+			goalsFor, goalsAgainst, venue = goalsAgainst, goalsFor, "Away"
+		}
+		points := getPointsFromMatch(record, isHome, rules)
+		totalPoints += points
+		totalGoalDifference += goalsFor - goalsAgainst
+		formPoints = append(formPoints, points)
+		if len(formPoints) > reportFormWindow {
+			formPoints = formPoints[1:]
+		}
+		pointsInForm := 0
+		for _, pointsFromMatch := range formPoints {
+			pointsInForm += pointsFromMatch
+		}
+		reportMatch := ReportMatch{
+			Number:              idx + 1,
+			Venue:               venue,
+			Partner:             partner,
+			Opponent:            opponent,
+			GoalsFor:            goalsFor,
+			GoalsAgainst:        goalsAgainst,
+			Result:              getResultLetter(goalsFor, goalsAgainst),
+			Points:              points,
+			TotalPoints:         totalPoints,
+			TotalGoalDifference: totalGoalDifference,
+			RunningPPG:          round(float64(totalPoints)/float64(idx+1), 3),
+			FormPPG:             round(float64(pointsInForm)/float64(len(formPoints)), 3),
+		}
+		if !record.Date.IsZero() {
+			reportMatch.Date = record.Date.Format(dateLayouts[0])
+		}
+		sliceReportMatches = append(sliceReportMatches, reportMatch)
+	}
+	return sliceReportMatches
+}
+
+/*
+Gets splits of a team's (or individual's, if `solo` is true) matches by the given key i.e; venue, opponent.
+A match may count for more than one split (i.e; both opponents of an individual). Splits are in alphabetical order.
+*/
+func getReportSplits(records []RawData, team string, solo bool, getSplits func(record RawData) []string, rules ScoringRules) []ReportSplit {
+	mapStatsBySplit := map[string]StatsAbs{}
+	for _, record := range filterByTeams(records, []string{team}, solo) {
+		isHome, _, _ := getMatchSide(record, team, solo)
+		for _, split := range getSplits(record) {
+			stats := mapStatsBySplit[split]
+			stats.Team = split
+			mapStatsBySplit[split] = addMatchToStatsAbs(stats, record, isHome, rules)
+		}
+	}
+	state := AggregateState{MapStatsByTeam: mapStatsBySplit}
+	sliceAbsStats := state.getAbsoluteStats()
+	sliceNormStats := getNormalizedStats(sliceAbsStats)
+	sliceReportSplits := []ReportSplit{}
+	for idx, obj := range sliceAbsStats {
+		tempReportSplit := ReportSplit{
+			Split:        obj.Team,
+			GamesPlayed:  obj.GamesPlayed,
+			Wins:         obj.Wins,
+			Draws:        obj.Draws,
+			Losses:       obj.Losses,
+			GoalsScored:  obj.GoalsScored,
+			GoalsAllowed: obj.GoalsAllowed,
+			Points:       obj.Points,
+			PPG:          sliceNormStats[idx].PPG,
+		}
+		sliceReportSplits = append(sliceReportSplits, tempReportSplit)
+	}
+	return sliceReportSplits
+}
+
+// Gets the longest and current streaks of matches (in order of occurence) i.e; wins, unbeaten, clean sheets
+func getReportStreaks(sliceReportMatches []ReportMatch) []ReportStreak {
+	conditions := []struct {
+		Streak     string
+		IsInStreak func(obj ReportMatch) bool
+	}{
+		{"Wins", func(obj ReportMatch) bool { return obj.Result == "W" }},
+		{"Unbeaten", func(obj ReportMatch) bool { return obj.Result != "L" }},
+		{"Draws", func(obj ReportMatch) bool { return obj.Result == "D" }},
+		{"Winless", func(obj ReportMatch) bool { return obj.Result != "W" }},
+		{"Losses", func(obj ReportMatch) bool { return obj.Result == "L" }},
+		{"Scoring", func(obj ReportMatch) bool { return obj.GoalsFor > 0 }},
+		{"Clean sheets", func(obj ReportMatch) bool { return obj.GoalsAgainst == 0 }},
+	}
+	sliceReportStreaks := []ReportStreak{}
+	for _, condition := range conditions {
+		reportStreak := ReportStreak{Streak: condition.Streak}
+		for _, obj := range sliceReportMatches {
+			if condition.IsInStreak(obj) {
+				reportStreak.Current++
+			} else {
+				reportStreak.Current = 0
+			}
+			if reportStreak.Current > reportStreak.Longest {
+				reportStreak.Longest = reportStreak.Current
+			}
+		}
+		sliceReportStreaks = append(sliceReportStreaks, reportStreak)
+	}
+	return sliceReportStreaks
+}
+
+/*
+Gets the best results (wins, by biggest goal difference then most goals scored) if `best` is true, or else the worst
+results (losses, by biggest goal difference then most goals allowed). Ties keep the order of occurence.
+*/
+func getBestOrWorstResults(sliceReportMatches []ReportMatch, best bool, n int) []ReportMatch {
+	sliceResults := []ReportMatch{}
+	for _, obj := range sliceReportMatches {
+		if (best && obj.Result == "W") || (!best && obj.Result == "L") {
+			sliceResults = append(sliceResults, obj)
+		}
+	}
+	sort.SliceStable(sliceResults, func(i, j int) bool {
+		marginOfI := sliceResults[i].GoalsFor - sliceResults[i].GoalsAgainst
+		marginOfJ := sliceResults[j].GoalsFor - sliceResults[j].GoalsAgainst
+		if !best {
+			if marginOfI != marginOfJ {
+				return marginOfI < marginOfJ
+			}
+			return sliceResults[i].GoalsAgainst > sliceResults[j].GoalsAgainst
+		}
+		if marginOfI != marginOfJ {
+			return marginOfI > marginOfJ
+		}
+		return sliceResults[i].GoalsFor > sliceResults[j].GoalsFor
+	})
+	if len(sliceResults) > n {
+		sliceResults = sliceResults[:n]
+	}
+	return sliceResults
+}
+
+// Gets the form chart of matches, as a sparkline (for Markdown) and as points of a 600x120 polyline (for HTML)
+func getFormChart(sliceReportMatches []ReportMatch) (string, string) {
+	maxPoints := 3.0 // Points for a win, which shootouts can't exceed (see `checkPipelineOptions`)
+	bars := []rune("▁▂▃▄▅▆▇█")
+	sparkline, polylinePoints := "", []string{}
+	for idx, obj := range sliceReportMatches {
+		level := int(obj.FormPPG / maxPoints * float64(len(bars)-1))
+		sparkline += string(bars[level])
+		x := 600.0
+		if len(sliceReportMatches) > 1 {
+			x = float64(idx) * 600 / float64(len(sliceReportMatches)-1)
+		}
+		y := 120 - obj.FormPPG/maxPoints*120
+		polylinePoints = append(polylinePoints, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return sparkline, strings.Join(polylinePoints, " ")
+}
+
+// Gets table of a report from a header and slice of objects having the `ListStringifiedValues` method
+func getReportTable(title string, header []string, sliceRecords []interface{ ListStringifiedValues() []string }) ReportTable {
+	reportTable := ReportTable{Title: title, Header: header, Rows: [][]string{}}
+	for _, obj := range sliceRecords {
+		reportTable.Rows = append(reportTable.Rows, obj.ListStringifiedValues())
+	}
+	return reportTable
+}
+
+// Gets table of a report having matches. The `Partner` column is left out for teams
+func getReportMatchesTable(title string, sliceReportMatches []ReportMatch, solo bool) ReportTable {
+	sliceRecords := []interface{ ListStringifiedValues() []string }{}
+	for _, obj := range sliceReportMatches {
+		sliceRecords = append(sliceRecords, obj)
+	}
+	reportTable := getReportTable(title, structs.Names(&ReportMatch{}), sliceRecords)
+	if !solo {
+		idxPartner := 3 // Index of `Partner` in `ReportMatch`
+		reportTable.Header = append(reportTable.Header[:idxPartner:idxPartner], reportTable.Header[idxPartner+1:]...)
+		for idx, row := range reportTable.Rows {
+			reportTable.Rows[idx] = append(row[:idxPartner:idxPartner], row[idxPartner+1:]...)
+		}
+	}
+	return reportTable
+}
+
+// Gets table of a report having splits, sorted by games played (most first) if `byGamesPlayed` is true
+func getReportSplitsTable(title string, sliceReportSplits []ReportSplit, byGamesPlayed bool) ReportTable {
+	if byGamesPlayed {
+		sort.SliceStable(sliceReportSplits, func(i, j int) bool {
+			return sliceReportSplits[i].GamesPlayed > sliceReportSplits[j].GamesPlayed
+		})
+	}
+	sliceRecords := []interface{ ListStringifiedValues() []string }{}
+	for _, obj := range sliceReportSplits {
+		sliceRecords = append(sliceRecords, obj)
+	}
+	return getReportTable(title, structs.Names(&ReportSplit{}), sliceRecords)
+}
+
+/*
+Gets report of a team (or individual, if `solo` is true) from `RawData` records i.e; their row in the absolute and
+normalized stats, all matches with running totals, splits by venue, opponent (and partner, for individuals), streaks,
+form chart, best/worst results and most-faced opponents.
+*/
+func getTeamReport(records []RawData, filename string, team string, solo bool, options PipelineOptions) TeamReport {
+	sliceAbsStats := getAbsoluteStats(records, options.ScoringRules)
+	if solo {
+		sliceAbsStats = getAbsoluteStatsByIndividual(records, sliceAbsStats)
+	}
+	sliceNormStats := getRankedNormStats(sliceAbsStats, options)
+	sliceAbsStats = attachRankingToAbsStats(sortAbsStatsByMetric(sliceAbsStats))
+	report := TeamReport{Title: team, Subtitle: removeExtension(filename)}
+	for _, obj := range sliceAbsStats {
+		if obj.Team == team {
+			report.Tables = append(report.Tables, ReportTable{Title: "Absolute Stats", Header: structs.Names(&StatsAbs{}), Rows: [][]string{obj.ListStringifiedValues()}})
+		}
+	}
+	for _, obj := range sliceNormStats {
+		if obj.Team == team {
+			report.Tables = append(report.Tables, ReportTable{Title: "Normalized Stats", Header: structs.Names(&StatsNorm{}), Rows: [][]string{obj.ListStringifiedValues()}})
+		}
+	}
+
+	sliceReportMatches := getReportMatches(records, team, solo, options.ScoringRules)
+	for _, obj := range sliceReportMatches {
+		report.Form += obj.Result
+	}
+	report.FormChart, report.FormChartPoints = getFormChart(sliceReportMatches)
+	sliceStreaks := []interface{ ListStringifiedValues() []string }{}
+	for _, obj := range getReportStreaks(sliceReportMatches) {
+		sliceStreaks = append(sliceStreaks, obj)
+	}
+	report.Tables = append(report.Tables, getReportTable("Streaks", structs.Names(&ReportStreak{}), sliceStreaks))
+	report.Tables = append(report.Tables, getReportMatchesTable("Best Results", getBestOrWorstResults(sliceReportMatches, true, reportTopResults), solo))
+	report.Tables = append(report.Tables, getReportMatchesTable("Worst Results", getBestOrWorstResults(sliceReportMatches, false, reportTopResults), solo))
+
+	getVenue := func(record RawData) []string {
+		isHome, _, _ := getMatchSide(record, team, solo)
+		if isHome {
+			return []string{"Home"}
+		}
+		return []string{"Away"}
+	}
+	getOpponents := func(record RawData) []string {
+		_, _, opponent := getMatchSide(record, team, solo)
+		if solo {
+			return regexp.MustCompile(`[A-Z][^A-Z]*`).FindAllString(opponent, -1)
+		}
+		return []string{opponent}
+	}
+	report.Tables = append(report.Tables, getReportSplitsTable("Venue", getReportSplits(records, team, solo, getVenue, options.ScoringRules), false))
+	sliceOpponentSplits := getReportSplitsTable("Opponents (most faced first)", getReportSplits(records, team, solo, getOpponents, options.ScoringRules), true)
+	if solo {
+		getPartners := func(record RawData) []string {
+			_, partner, _ := getMatchSide(record, team, solo)
+			return []string{partner}
+		}
+		report.Tables = append(report.Tables, getReportSplitsTable("Partners (most played with first)", getReportSplits(records, team, solo, getPartners, options.ScoringRules), true))
+	}
+	report.Tables = append(report.Tables, sliceOpponentSplits)
+	report.Tables = append(report.Tables, getReportMatchesTable("Matches", sliceReportMatches, solo))
+	return report
+}
+
+const teamReportMarkdownTemplate = `# {{.Title}}
+{{.Subtitle}}
+
+## Form
+` + "`{{.Form}}`" + `
+
+Rolling PPG (last {{.FormWindow}} games) by match: ` + "`{{.FormChart}}`" + `
+{{range .Tables}}
+## {{.Title}}
+| {{join .Header " | "}} |
+|{{range .Header}} --- |{{end}}
+{{range .Rows}}| {{join . " | "}} |
+{{end}}{{end}}`
+
+const teamReportHtmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.Subtitle}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 16px; }
+th, td { border: 1px solid #999; padding: 4px 8px; text-align: right; }
+th { background: #eee; }
+.form { font-family: monospace; word-break: break-all; }
+.chart { border: 1px solid #999; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Subtitle}}</p>
+<h2>Form</h2>
+<p class="form">{{.Form}}</p>
+<p>Rolling PPG (last {{.FormWindow}} games) by match:</p>
+<svg class="chart" width="600" height="120" viewBox="0 0 600 120" preserveAspectRatio="none">
+<polyline fill="none" stroke="#36c" stroke-width="2" points="{{.FormChartPoints}}"/>
+</svg>
+{{range .Tables}}<h2>{{.Title}}</h2>
+<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`
+
+// Saves report of a team (or individual) to file, as Markdown or HTML (if `format` is "html")
+func saveTeamReport(report TeamReport, format string, filepath string) {
+	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0777)
+	if err != nil {
+		log.Fatalln("Couldn't create the report file", err)
+	}
+	defer file.Close()
+	data := map[string]interface{}{
+		"Title":           report.Title,
+		"Subtitle":        report.Subtitle,
+		"Form":            report.Form,
+		"FormWindow":      reportFormWindow,
+		"FormChart":       report.FormChart,
+		"FormChartPoints": report.FormChartPoints,
+		"Tables":          report.Tables,
+	}
+	if format == "html" {
+		err = template.Must(template.New("report").Parse(teamReportHtmlTemplate)).Execute(file, data)
+	} else {
+		funcs := texttemplate.FuncMap{"join": strings.Join}
+		err = texttemplate.Must(texttemplate.New("report").Funcs(funcs).Parse(teamReportMarkdownTemplate)).Execute(file, data)
+	}
+	if err != nil {
+		log.Fatalln("Couldn't write the report file", err)
+	}
+}
+
+/*
+Command that saves a detailed report of a team (or individual) of a data file, as Markdown or HTML.
+Reports of all teams (or individuals) are saved if no team is given.
+*/
+func runReportCommand(args []string) {
+	flagSet := flag.NewFlagSet("report", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	filename := flagSet.String("file", "", "Name of the data file (in the data folder) to report on")
+	team := flagSet.String("team", "", "Team (or individual, with -individuals) to report on. Reports on all of them if empty")
+	solo := flagSet.Bool("individuals", false, "Report on individuals instead of teams (for 2v2 data files)")
+	format := flagSet.String("format", "markdown", "Format of the report (markdown or html)")
+	output := flagSet.String("output", "", "Path to the report file (defaults to a file in the results folder, only if -team is given)")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+
+	if *filename == "" {
+		log.Fatalln("Use -file to choose the data file to report on")
+	}
+	if *format != "markdown" && *format != "html" {
+		log.Fatalln("-format must be markdown or html")
+	}
+	records := loadRawRecords(pathDataFolder+"/"+*filename, *options)
+	if *solo && !isValid2v2Naming(records) {
+		log.Fatalln("Incorrect team-names! Individuals of '" + *filename + "' can't be reported on")
+	}
+	teams := getUniqueTeamNames(records)
+	if *solo {
+		teams = getUniqueIndividualNames(records)
+	}
+	if *team != "" {
+		if !stringInSlice(*team, teams) {
+			log.Fatalln("'" + *team + "' didn't play in '" + *filename + "'")
+		}
+		teams = []string{*team}
+	} else if *output != "" {
+		log.Fatalln("-output can only be used along with -team")
+	}
+	extension := ".md"
+	if *format == "html" {
+		extension = ".html"
+	}
+	for _, name := range teams {
+		filepath := pathResultsFolder + "/" + removeExtension(*filename) + " - Report - " + name + extension
+		if *output != "" {
+			filepath = *output
+		}
+		saveTeamReport(getTeamReport(records, *filename, name, *solo, *options), *format, filepath)
+		fmt.Println("Saved report of '" + name + "' to '" + filepath + "'")
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Constants - Stopping criteria for the iterative opponent adjustment
+const (
+	adjustmentMaxIterations = 1000
+	adjustmentTolerance     = 1e-9
+)
+
+// Struct to store strength of schedule and opponent-adjusted PPG
+type StrengthOfSchedule struct {
+	Rank        int
+	Team        string
+	GamesPlayed int
+	PPG         float64
+	OpponentPPG float64 // Average PPG of opponents faced (counted once per game)
+	Elo         float64
+	OpponentElo float64 // Average Elo rating of opponents faced (counted once per game)
+	AdjustedPPG float64 // PPG corrected for quality of opponents faced
+}
+
+/*
+Method that gets slice of stringified elements of `StrengthOfSchedule` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `StrengthOfSchedule` struct to CSV file.
+*/
+func (obj StrengthOfSchedule) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Rank))
+	values = append(values, obj.Team)
+	values = append(values, strconv.Itoa(obj.GamesPlayed))
+	values = append(values, fmt.Sprintf("%g", obj.PPG))
+	values = append(values, fmt.Sprintf("%g", obj.OpponentPPG))
+	values = append(values, fmt.Sprintf("%g", obj.Elo))
+	values = append(values, fmt.Sprintf("%g", obj.OpponentElo))
+	values = append(values, fmt.Sprintf("%g", obj.AdjustedPPG))
+	return values
+}
+
+// Gets map of team -> slice of opponents faced (one element per game) from `RawData` records
+func getOpponentsByTeam(records []RawData) map[string][]string {
+	mapOpponentsByTeam := map[string][]string{}
+	for _, record := range records {
+		mapOpponentsByTeam[record.HomeTeam] = append(mapOpponentsByTeam[record.HomeTeam], record.AwayTeam)
+		mapOpponentsByTeam[record.AwayTeam] = append(mapOpponentsByTeam[record.AwayTeam], record.HomeTeam)
+	}
+	return mapOpponentsByTeam
+}
+
+// Gets average of values (looked up from `mapValueByTeam`) of the given opponents
+func getAverageOverOpponents(opponents []string, mapValueByTeam map[string]float64) float64 {
+	if len(opponents) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, opponent := range opponents {
+		sum += mapValueByTeam[opponent]
+	}
+	return sum / float64(len(opponents))
+}
+
+/*
+Gets map of team -> index of its connected component of the schedule i.e; teams linked (directly or through
+opponents) by games played. Teams of different components (i.e; separate 2v2 groups) never faced each other.
+*/
+func getScheduleComponents(sliceAbsStats []StatsAbs, mapOpponentsByTeam map[string][]string) map[string]int {
+	mapComponentByTeam := map[string]int{}
+	numComponents := 0
+	for _, obj := range sliceAbsStats {
+		if _, ok := mapComponentByTeam[obj.Team]; ok {
+			continue
+		}
+		mapComponentByTeam[obj.Team] = numComponents
+		queue := []string{obj.Team}
+		for len(queue) > 0 {
+			team := queue[0]
+			queue = queue[1:]
+			for _, opponent := range mapOpponentsByTeam[team] {
+				if _, ok := mapComponentByTeam[opponent]; !ok {
+					mapComponentByTeam[opponent] = numComponents
+					queue = append(queue, opponent)
+				}
+			}
+		}
+		numComponents++
+	}
+	return mapComponentByTeam
+}
+
+/*
+Gets opponent-adjusted PPG of each team i.e; AdjustedPPG = PPG + (average AdjustedPPG of opponents faced - LeaguePPG).
+Since each team's adjustment depends on its opponents' adjustments, the values are iterated until they converge.
+After every iteration, values are re-centred per connected component of the schedule (see `getScheduleComponents`),
+so that their games-weighted mean stays equal to the PPG of the component. Components can't be compared, so
+re-centring them together would leave each one drifting by an arbitrary offset.
+*/
+func getAdjustedPpg(sliceAbsStats []StatsAbs, mapOpponentsByTeam map[string][]string) map[string]float64 {
+	leaguePpg := getLeaguePpg(sliceAbsStats)
+	mapComponentByTeam := getScheduleComponents(sliceAbsStats, mapOpponentsByTeam)
+	mapPpgByTeam := map[string]float64{}
+	mapAdjustedPpgByTeam := map[string]float64{}
+	mapPointsByComponent, mapGamesPlayedByComponent := map[int]int{}, map[int]int{}
+	for _, obj := range sliceAbsStats {
+		ppg := float64(obj.Points) / float64(obj.GamesPlayed)
+		mapPpgByTeam[obj.Team] = ppg
+		mapAdjustedPpgByTeam[obj.Team] = ppg
+		mapPointsByComponent[mapComponentByTeam[obj.Team]] += obj.Points
+		mapGamesPlayedByComponent[mapComponentByTeam[obj.Team]] += obj.GamesPlayed
+	}
+	for iteration := 0; iteration < adjustmentMaxIterations; iteration++ {
+		mapNextAdjustedPpgByTeam := map[string]float64{}
+		mapWeightedSumByComponent := map[int]float64{}
+		for _, obj := range sliceAbsStats {
+			opponentAdjustedPpg := getAverageOverOpponents(mapOpponentsByTeam[obj.Team], mapAdjustedPpgByTeam)
+			target := mapPpgByTeam[obj.Team] + opponentAdjustedPpg - leaguePpg
+			// Damping avoids oscillation in schedules where teams only face one "side" of the league
+			nextValue := (mapAdjustedPpgByTeam[obj.Team] + target) / 2
+			mapNextAdjustedPpgByTeam[obj.Team] = nextValue
+			mapWeightedSumByComponent[mapComponentByTeam[obj.Team]] += nextValue * float64(obj.GamesPlayed)
+		}
+		maxChange := 0.0
+		for team, value := range mapNextAdjustedPpgByTeam {
+			component := mapComponentByTeam[team]
+			gamesPlayed := float64(mapGamesPlayedByComponent[component])
+			value += (float64(mapPointsByComponent[component]) - mapWeightedSumByComponent[component]) / gamesPlayed
+			maxChange = math.Max(maxChange, math.Abs(value-mapAdjustedPpgByTeam[team]))
+			mapNextAdjustedPpgByTeam[team] = value
+		}
+		mapAdjustedPpgByTeam = mapNextAdjustedPpgByTeam
+		if maxChange < adjustmentTolerance {
+			break
+		}
+	}
+	return mapAdjustedPpgByTeam
+}
+
+/*
+Gets slice of strength of schedule metrics from `RawData` records, absolute stats and Elo ratings of teams
+(see `getEloRatings`).
+Returns slice wherein each element of the slice is an object of the struct `StrengthOfSchedule`
+*/
+func getStrengthOfSchedule(records []RawData, sliceAbsStats []StatsAbs, mapEloByTeam map[string]float64) []StrengthOfSchedule {
+	mapOpponentsByTeam := getOpponentsByTeam(records)
+	mapAdjustedPpgByTeam := getAdjustedPpg(sliceAbsStats, mapOpponentsByTeam)
+	mapPpgByTeam := map[string]float64{}
+	for _, obj := range sliceAbsStats {
+		mapPpgByTeam[obj.Team] = float64(obj.Points) / float64(obj.GamesPlayed)
+	}
+	sliceStrengthOfSchedule := []StrengthOfSchedule{}
+	for _, obj := range sliceAbsStats {
+		opponents := mapOpponentsByTeam[obj.Team]
+		tempObj := StrengthOfSchedule{
+			Team:        obj.Team,
+			GamesPlayed: obj.GamesPlayed,
+			PPG:         round(mapPpgByTeam[obj.Team], 4),
+			OpponentPPG: round(getAverageOverOpponents(opponents, mapPpgByTeam), 4),
+			Elo:         round(mapEloByTeam[obj.Team], 1),
+			OpponentElo: round(getAverageOverOpponents(opponents, mapEloByTeam), 1),
+			AdjustedPPG: round(mapAdjustedPpgByTeam[obj.Team], 4),
+		}
+		sliceStrengthOfSchedule = append(sliceStrengthOfSchedule, tempObj)
+	}
+	return sliceStrengthOfSchedule
+}
+
+// Sorts strength of schedule based on `AdjustedPPG`, and attaches ranking
+func sortAndRankStrengthOfSchedule(sliceStrengthOfSchedule []StrengthOfSchedule) []StrengthOfSchedule {
+	sort.SliceStable(sliceStrengthOfSchedule, func(i, j int) bool {
+		return sliceStrengthOfSchedule[i].AdjustedPPG > sliceStrengthOfSchedule[j].AdjustedPPG
+	})
+	for idx := range sliceStrengthOfSchedule {
+		sliceStrengthOfSchedule[idx].Rank = idx + 1
+	}
+	return sliceStrengthOfSchedule
+}
+
+// Saves slice having objects of `StrengthOfSchedule` struct to CSV file
+func saveStrengthOfScheduleToCsv(sliceData []StrengthOfSchedule, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&StrengthOfSchedule{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetAdjustedPpgOfDisconnectedSchedules(t *testing.T) {
+	groupA := []RawData{
+		{HomeTeam: "Arsenal", HomeGoals: 2, AwayGoals: 0, AwayTeam: "Chelsea"},
+		{HomeTeam: "Chelsea", HomeGoals: 1, AwayGoals: 1, AwayTeam: "Everton"},
+		{HomeTeam: "Everton", HomeGoals: 0, AwayGoals: 3, AwayTeam: "Arsenal"},
+		{HomeTeam: "Arsenal", HomeGoals: 1, AwayGoals: 2, AwayTeam: "Chelsea"},
+	}
+	// Never faces any team of group A
+	groupB := []RawData{
+		{HomeTeam: "Fulham", HomeGoals: 0, AwayGoals: 0, AwayTeam: "Leeds"},
+		{HomeTeam: "Leeds", HomeGoals: 4, AwayGoals: 1, AwayTeam: "Wolves"},
+		{HomeTeam: "Wolves", HomeGoals: 2, AwayGoals: 1, AwayTeam: "Fulham"},
+	}
+	getAdjustedPpgOf := func(records []RawData) map[string]float64 {
+		return getAdjustedPpg(getAbsoluteStats(records, defaultScoringRules), getOpponentsByTeam(records))
+	}
+	mapAdjustedPpgAlone := getAdjustedPpgOf(groupA)
+	mapAdjustedPpgTogether := getAdjustedPpgOf(append(append([]RawData{}, groupA...), groupB...))
+	for team, adjustedPpg := range mapAdjustedPpgAlone {
+		if math.Abs(mapAdjustedPpgTogether[team]-adjustedPpg) > 1e-6 {
+			t.Errorf("%s: got %g alongside a separate group, want %g as without it", team, mapAdjustedPpgTogether[team], adjustedPpg)
+		}
+	}
+	// Games-weighted mean of each group stays equal to the group's PPG i.e; 8 points in 6 games of group B
+	weightedSum := 0.0
+	for _, obj := range getAbsoluteStats(groupB, defaultScoringRules) {
+		weightedSum += mapAdjustedPpgTogether[obj.Team] * float64(obj.GamesPlayed)
+	}
+	if math.Abs(weightedSum/6-8.0/6) > 1e-6 {
+		t.Errorf("group B: got games-weighted mean %g, want %g", weightedSum/6, 8.0/6)
+	}
+}
+
+func TestGetScheduleComponents(t *testing.T) {
+	records := []RawData{
+		{HomeTeam: "Arsenal", AwayTeam: "Chelsea"},
+		{HomeTeam: "Everton", AwayTeam: "Fulham"},
+		{HomeTeam: "Chelsea", AwayTeam: "Leeds"},
+	}
+	mapComponentByTeam := getScheduleComponents(getAbsoluteStats(records, defaultScoringRules), getOpponentsByTeam(records))
+	if mapComponentByTeam["Arsenal"] != mapComponentByTeam["Leeds"] || mapComponentByTeam["Everton"] != mapComponentByTeam["Fulham"] ||
+		mapComponentByTeam["Arsenal"] == mapComponentByTeam["Everton"] {
+		t.Errorf("got components %v, want {Arsenal, Chelsea, Leeds} and {Everton, Fulham}", mapComponentByTeam)
+	}
+}
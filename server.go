@@ -0,0 +1,466 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Dashboard served at "/", which renders tables and charts using the API
+//
+//go:embed dashboard.html
+var dashboardHtml []byte
+
+// Struct to store `RawData` records of every data file loaded by the server, guarded for concurrent requests
+type DataCache struct {
+	mutex            sync.RWMutex
+	addMutex         sync.Mutex // Matches are added one at a time, as adding recomputes tables
+	options          PipelineOptions
+	store            *ResultStore
+	mapRecordsByFile map[string][]RawData
+}
+
+// Struct to store a match from the perspective of a team (if any)
+type MatchView struct {
+	Date      string
+	Stage     string
+	HomeTeam  string
+	HomeGoals int
+	AwayGoals int
+	AwayTeam  string
+	Score     string // Eg: "1-1 (4-3 pens)"
+	Result    string // "W", "L" or "D" for the team whose matches are listed. Empty otherwise
+}
+
+// Struct to store a match posted to "/api/matches", to be added to a data file
+type NewMatchRequest struct {
+	File      string
+	HomeTeam  string
+	HomeGoals int
+	AwayGoals int
+	AwayTeam  string
+	Date      string // Optional
+}
+
+// Struct to store head-to-head record between two teams/individuals
+type HeadToHead struct {
+	Team1       string
+	Team2       string
+	GamesPlayed int
+	Team1Wins   int
+	Draws       int
+	Team2Wins   int
+	Team1Goals  int
+	Team2Goals  int
+	Matches     []MatchView
+}
+
+// Loads (or reloads) `RawData` records of every data file into the cache
+func (cache *DataCache) reload() {
+	mapRecordsByFile := map[string][]RawData{}
+	for _, filename := range getListOfDataFilenames() {
+		mapRecordsByFile[filename] = loadRawRecords(pathDataFolder+"/"+filename, cache.options)
+	}
+	cache.mutex.Lock()
+	cache.mapRecordsByFile = mapRecordsByFile
+	cache.mutex.Unlock()
+}
+
+// Reloads `RawData` records of a single data file into the cache
+func (cache *DataCache) reloadFile(filename string) {
+	records := loadRawRecords(pathDataFolder+"/"+filename, cache.options)
+	cache.mutex.Lock()
+	cache.mapRecordsByFile[filename] = records
+	cache.mutex.Unlock()
+}
+
+// Gets `RawData` records of a data file. Returns false if the data file isn't loaded
+func (cache *DataCache) getRecords(filename string) ([]RawData, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	records, ok := cache.mapRecordsByFile[filename]
+	return records, ok
+}
+
+// Gets names of loaded data files, in alphabetical order
+func (cache *DataCache) getFilenames() []string {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	filenames := []string{}
+	for filename := range cache.mapRecordsByFile {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+/*
+Filters `RawData` records by the query parameters "from" and "to" (dates, inclusive). Records without a date
+are left out if either parameter is given.
+*/
+func filterRecordsByDateRange(records []RawData, from string, to string) ([]RawData, error) {
+	if from == "" && to == "" {
+		return records, nil
+	}
+	var fromDate, toDate time.Time
+	var err error
+	if from != "" {
+		if fromDate, err = parseDate(from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if toDate, err = parseDate(to); err != nil {
+			return nil, err
+		}
+	}
+	return filterByDateRange(records, fromDate, toDate), nil
+}
+
+// Gets a match from the perspective of `team` (if non-empty), having the score used in tables
+func getMatchView(record RawData, team string, rules ScoringRules) MatchView {
+	homeGoals, awayGoals := getScoreForTable(record, rules)
+	matchView := MatchView{
+		Stage:     record.Stage,
+		HomeTeam:  record.HomeTeam,
+		HomeGoals: homeGoals,
+		AwayGoals: awayGoals,
+		AwayTeam:  record.AwayTeam,
+		Score:     getScoreline(record),
+	}
+	if !record.Date.IsZero() {
+		matchView.Date = record.Date.Format(dateLayouts[0])
+	}
+	side, otherSide := record.HomeTeam, record.AwayTeam
+	if team == record.AwayTeam || (team != record.HomeTeam && individualInTeam(team, record.AwayTeam)) {
+		side, otherSide = record.AwayTeam, record.HomeTeam
+	}
+	if team != "" {
+		switch getMatchWinnerForTable(record, rules) {
+		case side:
+			matchView.Result = "W"
+		case otherSide:
+			matchView.Result = "L"
+		default:
+			matchView.Result = "D"
+		}
+	}
+	return matchView
+}
+
+// Returns true if the team (or individual, if `solo` is true) played in the match
+func teamInMatch(record RawData, team string, solo bool) bool {
+	if solo {
+		return individualInTeam(team, record.HomeTeam) || individualInTeam(team, record.AwayTeam)
+	}
+	return record.HomeTeam == team || record.AwayTeam == team
+}
+
+/*
+Gets head-to-head record between two teams (or individuals, if `solo` is true) from `RawData` records.
+Matches where both individuals are partners are left out.
+*/
+func getHeadToHead(records []RawData, team1 string, team2 string, solo bool, rules ScoringRules) HeadToHead {
+	headToHead := HeadToHead{Team1: team1, Team2: team2, Matches: []MatchView{}}
+	for _, record := range records {
+		isHome1, isAway1 := record.HomeTeam == team1, record.AwayTeam == team1
+		isHome2, isAway2 := record.HomeTeam == team2, record.AwayTeam == team2
+		if solo {
+			isHome1, isAway1 = individualInTeam(team1, record.HomeTeam), individualInTeam(team1, record.AwayTeam)
+			isHome2, isAway2 = individualInTeam(team2, record.HomeTeam), individualInTeam(team2, record.AwayTeam)
+		}
+		homeGoals, awayGoals := getScoreForTable(record, rules)
+		var goals1, goals2 int
+		var side1 string
+		if isHome1 && isAway2 {
+			goals1, goals2, side1 = homeGoals, awayGoals, record.HomeTeam
+		} else if isAway1 && isHome2 {
+			goals1, goals2, side1 = awayGoals, homeGoals, record.AwayTeam
+		} else {
+			continue
+		}
+		headToHead.GamesPlayed++
+		headToHead.Team1Goals += goals1
+		headToHead.Team2Goals += goals2
+		if winner := getMatchWinnerForTable(record, rules); winner == side1 {
+			headToHead.Team1Wins++
+		} else if winner != "" {
+			headToHead.Team2Wins++
+		} else {
+			headToHead.Draws++
+		}
+		matchView := getMatchView(record, "", rules)
+		headToHead.Matches = append(headToHead.Matches, matchView)
+	}
+	return headToHead
+}
+
+// Writes a value as JSON response
+func writeJson(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Println("Couldn't write the response", err)
+	}
+}
+
+/*
+Gets `RawData` records of the data file given by the query parameter "file", filtered by the query parameters "from"
+and "to". Writes an error response and returns false if the data file doesn't exist or the filters are invalid.
+*/
+func (cache *DataCache) getRequestedRecords(w http.ResponseWriter, r *http.Request) ([]RawData, bool) {
+	filename := r.URL.Query().Get("file")
+	records, ok := cache.getRecords(filename)
+	if !ok {
+		http.Error(w, "Unknown data file '"+filename+"'", http.StatusNotFound)
+		return nil, false
+	}
+	records, err := filterRecordsByDateRange(records, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid date filter: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return records, true
+}
+
+// Gets ranked absolute and normalized stats of teams (or individuals, if `solo` is true), as in the results folder
+func getRankedStats(records []RawData, options PipelineOptions, solo bool) ([]StatsAbs, []StatsNorm) {
+	sliceAbsStats := getAbsoluteStats(records, options.ScoringRules)
+	if solo {
+		sliceAbsStats = getAbsoluteStatsByIndividual(records, sliceAbsStats)
+	}
+	sliceNormStats := getRankedNormStats(sliceAbsStats, options)
+	sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
+	sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
+	return sliceAbsStats, sliceNormStats
+}
+
+// Handles "/api/files" i.e; lists the loaded data files
+func (cache *DataCache) handleFiles(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, cache.getFilenames())
+}
+
+/*
+Handles "/api/tables" i.e; absolute stats (default) or normalized stats (if the query parameter "kind" is "normalized")
+of teams. Query parameters "from", "to" and "min-games" filter the table.
+*/
+func (cache *DataCache) handleTables(w http.ResponseWriter, r *http.Request) {
+	cache.writeStatsTable(w, r, false)
+}
+
+// Handles "/api/individuals" i.e; same as "/api/tables", for individuals of 2v2 data files
+func (cache *DataCache) handleIndividuals(w http.ResponseWriter, r *http.Request) {
+	cache.writeStatsTable(w, r, true)
+}
+
+// Writes absolute/normalized stats of teams (or individuals, if `solo` is true) as per the query parameters
+func (cache *DataCache) writeStatsTable(w http.ResponseWriter, r *http.Request, solo bool) {
+	records, ok := cache.getRequestedRecords(w, r)
+	if !ok {
+		return
+	}
+	if solo && (!filenameContains2v2(r.URL.Query().Get("file")) || !isValid2v2Naming(records)) {
+		http.Error(w, "Individuals' stats are only available for 2v2 data files", http.StatusBadRequest)
+		return
+	}
+	options := cache.options
+	if minGames := r.URL.Query().Get("min-games"); minGames != "" {
+		minGamesPlayed, err := strconv.Atoi(minGames)
+		if err != nil {
+			http.Error(w, "Invalid min-games '"+minGames+"'", http.StatusBadRequest)
+			return
+		}
+		options.MinGamesPlayed = minGamesPlayed
+	}
+	sliceAbsStats, sliceNormStats := getRankedStats(records, options, solo)
+	if r.URL.Query().Get("kind") == "normalized" {
+		writeJson(w, sliceNormStats)
+	} else {
+		writeJson(w, sliceAbsStats)
+	}
+}
+
+/*
+Handles "/api/form" i.e; latest form of teams (or individuals, if the query parameter "entity" is "individual").
+Query parameter "n" is the number of latest games considered (defaults to 10).
+*/
+func (cache *DataCache) handleForm(w http.ResponseWriter, r *http.Request) {
+	records, ok := cache.getRequestedRecords(w, r)
+	if !ok {
+		return
+	}
+	nLatestGames := 10
+	if n := r.URL.Query().Get("n"); n != "" {
+		var err error
+		if nLatestGames, err = strconv.Atoi(n); err != nil || nLatestGames < 1 {
+			http.Error(w, "Invalid n '"+n+"'", http.StatusBadRequest)
+			return
+		}
+	}
+	sliceLatestForm := []LatestForm{}
+	if r.URL.Query().Get("entity") == entityIndividual {
+		sliceLatestForm = getLatestFormSolo(records, nLatestGames, cache.options.ScoringRules)
+	} else {
+		sliceLatestForm = getLatestForm(records, nLatestGames, cache.options.ScoringRules)
+	}
+	sliceLatestForm = sortLatestFormByMetric(sliceLatestForm)
+	sliceLatestForm = attachRankingToLatestForm(sliceLatestForm)
+	writeJson(w, sliceLatestForm)
+}
+
+/*
+Handles "/api/head-to-head" i.e; head-to-head record between the query parameters "team1" and "team2"
+(individuals, if the query parameter "entity" is "individual").
+*/
+func (cache *DataCache) handleHeadToHead(w http.ResponseWriter, r *http.Request) {
+	records, ok := cache.getRequestedRecords(w, r)
+	if !ok {
+		return
+	}
+	team1, team2 := r.URL.Query().Get("team1"), r.URL.Query().Get("team2")
+	if team1 == "" || team2 == "" {
+		http.Error(w, "Both team1 and team2 are required", http.StatusBadRequest)
+		return
+	}
+	writeJson(w, getHeadToHead(records, team1, team2, r.URL.Query().Get("entity") == entityIndividual, cache.options.ScoringRules))
+}
+
+/*
+Handles "/api/matches" i.e; matches of the query parameter "team" (all matches if empty), from the team's perspective.
+Query parameter "venue" ("home" or "away") keeps matches at that venue only, and "entity" works as in "/api/form".
+*/
+func (cache *DataCache) handleMatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		cache.handleAddMatch(w, r)
+		return
+	}
+	records, ok := cache.getRequestedRecords(w, r)
+	if !ok {
+		return
+	}
+	team, venue := r.URL.Query().Get("team"), r.URL.Query().Get("venue")
+	solo := r.URL.Query().Get("entity") == entityIndividual
+	sliceMatchViews := []MatchView{}
+	for _, record := range records {
+		if team != "" && !teamInMatch(record, team, solo) {
+			continue
+		}
+		isHome := record.HomeTeam == team || (solo && individualInTeam(team, record.HomeTeam))
+		if (venue == "home" && !isHome) || (venue == "away" && isHome) {
+			continue
+		}
+		sliceMatchViews = append(sliceMatchViews, getMatchView(record, team, cache.options.ScoringRules))
+	}
+	writeJson(w, sliceMatchViews)
+}
+
+/*
+Checks if a request comes from the dashboard itself, and not from a page of another site in the browser i.e; its
+"Origin" header (if any) has the same host as the request. Browsers always send the header with cross-origin POSTs.
+*/
+func isSameOriginRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originUrl, err := url.Parse(origin)
+	return err == nil && originUrl.Host == r.Host
+}
+
+/*
+Handles POST to "/api/matches" i.e; adds the match in the JSON body (see `NewMatchRequest`) to its data file, the same
+way as the "add" command, and responds with the updated absolute stats of teams. Only same-origin requests having a
+JSON body are accepted, since a cross-site form can neither send JSON nor pass the "Origin" check.
+*/
+func (cache *DataCache) handleAddMatch(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if !isSameOriginRequest(r) {
+		http.Error(w, "Cross-origin requests aren't allowed", http.StatusForbidden)
+		return
+	}
+	var newMatch NewMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&newMatch); err != nil {
+		http.Error(w, "Invalid match: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := cache.getRecords(newMatch.File); !ok {
+		http.Error(w, "Unknown data file '"+newMatch.File+"'", http.StatusNotFound)
+		return
+	}
+	record := RawData{HomeTeam: newMatch.HomeTeam, HomeGoals: newMatch.HomeGoals, AwayGoals: newMatch.AwayGoals, AwayTeam: newMatch.AwayTeam}
+	if newMatch.Date != "" {
+		date, err := parseDate(newMatch.Date)
+		if err != nil {
+			http.Error(w, "Invalid date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		record.Date = date
+	}
+	cache.addMutex.Lock()
+	defer cache.addMutex.Unlock()
+	isValid, err := addMatch(newMatch.File, record, cache.options, cache.store)
+	if err != nil {
+		http.Error(w, "Couldn't add the match: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	cache.reloadFile(newMatch.File)
+	if !isValid {
+		http.Error(w, "Match was added, but '"+newMatch.File+"' now fails validation. See the validation report", http.StatusConflict)
+		return
+	}
+	records, _ := cache.getRecords(newMatch.File)
+	sliceAbsStats, _ := getRankedStats(records, cache.options, false)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJson(w, sliceAbsStats)
+}
+
+// Handles "/" i.e; serves the dashboard
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHtml)
+}
+
+// Gets the HTTP handler of the server i.e; the dashboard and the API
+func (cache *DataCache) getHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handleDashboard)
+	mux.HandleFunc("/api/files", cache.handleFiles)
+	mux.HandleFunc("/api/tables", cache.handleTables)
+	mux.HandleFunc("/api/individuals", cache.handleIndividuals)
+	mux.HandleFunc("/api/form", cache.handleForm)
+	mux.HandleFunc("/api/head-to-head", cache.handleHeadToHead)
+	mux.HandleFunc("/api/matches", cache.handleMatches)
+	return mux
+}
+
+// Command that serves the API and the dashboard, for viewing stats of the data folder in a browser
+func runServeCommand(args []string) {
+	flagSet := flag.NewFlagSet("serve", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	address := flagSet.String("addr", "localhost:8080", "Address to listen on")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+	cache := &DataCache{options: *options}
+	if options.DatabasePath != "" {
+		cache.store = openResultStore(options.DatabasePath, *options)
+	}
+	cache.reload()
+	fmt.Println("Serving stats of " + strconv.Itoa(len(cache.getFilenames())) + " data file/s at http://" + *address)
+	log.Fatalln(http.ListenAndServe(*address, cache.getHandler()))
+}
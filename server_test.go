@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAddMatchRejectsCrossSiteRequests(t *testing.T) {
+	body := `{"File": "FIFA19-2v2.csv", "HomeTeam": "AnkurNishant", "HomeGoals": 3, "AwayGoals": 1, "AwayTeam": "GaganRaghav"}`
+	testCases := []struct {
+		label       string
+		contentType string
+		origin      string
+		want        int
+	}{
+		{"form post", "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"plain text", "text/plain", "http://localhost:8080", http.StatusUnsupportedMediaType},
+		{"missing content type", "", "", http.StatusUnsupportedMediaType},
+		{"other origin", "application/json", "http://evil.example", http.StatusForbidden},
+		{"other port", "application/json; charset=utf-8", "http://localhost:9090", http.StatusForbidden},
+	}
+	cache := &DataCache{}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/matches", strings.NewReader(body))
+		if testCase.contentType != "" {
+			r.Header.Set("Content-Type", testCase.contentType)
+		}
+		if testCase.origin != "" {
+			r.Header.Set("Origin", testCase.origin)
+		}
+		w := httptest.NewRecorder()
+		cache.handleMatches(w, r)
+		if w.Code != testCase.want {
+			t.Errorf("%s: got status %d, want %d", testCase.label, w.Code, testCase.want)
+		}
+	}
+}
+
+func TestIsSameOriginRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/matches", nil)
+	if !isSameOriginRequest(r) {
+		t.Error("request without Origin was rejected")
+	}
+	r.Header.Set("Origin", "http://localhost:8080")
+	if !isSameOriginRequest(r) {
+		t.Error("same-origin request was rejected")
+	}
+	r.Header.Set("Origin", "null")
+	if isSameOriginRequest(r) {
+		t.Error("request having Origin 'null' was accepted")
+	}
+}
+
+func TestMatchResultsFollowShootoutMode(t *testing.T) {
+	records := []RawData{
+		// Chelsea win the shootout of a 1-1 draw
+		{HomeTeam: "Arsenal", HomeGoals: 1, AwayGoals: 1, AwayTeam: "Chelsea", HasShootout: true, HomePenalties: 3, AwayPenalties: 4},
+		// Arsenal win in extra time
+		{HomeTeam: "Chelsea", HomeGoals: 0, AwayGoals: 0, AwayTeam: "Arsenal", HasExtraTime: true, HomeGoalsAET: 0, AwayGoalsAET: 1},
+	}
+	shootoutPoints := defaultScoringRules
+	shootoutPoints.ShootoutMode = shootoutModePoints
+	regulationOnly := defaultScoringRules
+	regulationOnly.CountExtraTime = false
+	testCases := []struct {
+		label       string
+		rules       ScoringRules
+		wantResults []string // Results of Arsenal
+		wantWins    [3]int   // Arsenal wins, draws, Chelsea wins
+	}{
+		{"shootouts are draws", defaultScoringRules, []string{"D", "W"}, [3]int{1, 1, 0}},
+		{"shootouts get points", shootoutPoints, []string{"L", "W"}, [3]int{1, 0, 1}},
+		{"regulation score only", regulationOnly, []string{"D", "D"}, [3]int{0, 2, 0}},
+	}
+	for _, testCase := range testCases {
+		for idx, record := range records {
+			if got := getMatchView(record, "Arsenal", testCase.rules).Result; got != testCase.wantResults[idx] {
+				t.Errorf("%s, match %d: got %q, want %q", testCase.label, idx+1, got, testCase.wantResults[idx])
+			}
+		}
+		headToHead := getHeadToHead(records, "Arsenal", "Chelsea", false, testCase.rules)
+		if got := [3]int{headToHead.Team1Wins, headToHead.Draws, headToHead.Team2Wins}; got != testCase.wantWins {
+			t.Errorf("%s: got head-to-head %v, want %v", testCase.label, got, testCase.wantWins)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/structs"
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver (no cgo), registered as "sqlite"
+)
+
+// Constants - Kinds of entities whose computed tables are stored
+const (
+	entityTeam       = "team"
+	entityIndividual = "individual"
+)
+
+// Statements creating the schema of the database. Computed tables are created from their structs (see `createStatsTable`)
+var schemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS Runs (
+		RunId INTEGER PRIMARY KEY AUTOINCREMENT,
+		StartedAt TEXT NOT NULL,
+		Options TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS Matches (
+		SourceFile TEXT NOT NULL,
+		RecordNumber INTEGER NOT NULL,
+		ContentHash TEXT NOT NULL,
+		ImportedByRunId INTEGER NOT NULL REFERENCES Runs(RunId),
+		ImportedAt TEXT NOT NULL,
+		HomeTeam TEXT NOT NULL,
+		HomeGoals INTEGER NOT NULL,
+		AwayGoals INTEGER NOT NULL,
+		AwayTeam TEXT NOT NULL,
+		Date TEXT,
+		HalfTimeHomeGoals INTEGER,
+		HalfTimeAwayGoals INTEGER,
+		HomeGoalsAET INTEGER,
+		AwayGoalsAET INTEGER,
+		HomePenalties INTEGER,
+		AwayPenalties INTEGER,
+		Stage TEXT,
+		PRIMARY KEY (SourceFile, RecordNumber)
+	)`,
+}
+
+// Struct to store a connection to the database, along with the run that results are stored for
+type ResultStore struct {
+	db    *sql.DB
+	runId int64
+}
+
+/*
+Opens (or creates) the SQLite database at `filepath`, creates the schema if needed, and registers a new run having
+the given options. All computed tables stored through the returned `ResultStore` belong to this run.
+*/
+func openResultStore(filepath string, options PipelineOptions) *ResultStore {
+	db, err := sql.Open("sqlite", filepath)
+	if err != nil {
+		log.Fatalln("Couldn't open the database", err)
+	}
+	for _, statement := range schemaStatements {
+		if _, err := db.Exec(statement); err != nil {
+			log.Fatalln("Couldn't create the schema of the database", err)
+		}
+	}
+	createStatsTable(db, "AbsoluteStats", structs.Names(&StatsAbs{}))
+	createStatsTable(db, "NormalizedStats", structs.Names(&StatsNorm{}))
+	createStatsTable(db, "LatestForm", structs.Names(&LatestForm{}))
+	optionsJson, err := json.Marshal(options)
+	if err != nil {
+		log.Fatalln("Couldn't serialise the options of the run", err)
+	}
+	result, err := db.Exec("INSERT INTO Runs (StartedAt, Options) VALUES (?, ?)", time.Now().UTC().Format(time.RFC3339), string(optionsJson))
+	if err != nil {
+		log.Fatalln("Couldn't register the run in the database", err)
+	}
+	runId, err := result.LastInsertId()
+	if err != nil {
+		log.Fatalln("Couldn't register the run in the database", err)
+	}
+	return &ResultStore{db: db, runId: runId}
+}
+
+/*
+Creates a table of computed stats (if it doesn't exist) having the run, the data file and the kind of entity,
+followed by a column per attribute of the struct. "Team" is text, and other attributes are numeric.
+Columns of attributes added since the table was created are added to it (empty for earlier runs).
+*/
+func createStatsTable(db *sql.DB, table string, fields []string) {
+	columns := []string{"RunId INTEGER NOT NULL REFERENCES Runs(RunId)", "SourceFile TEXT NOT NULL", "Entity TEXT NOT NULL"}
+	for _, field := range fields {
+		columnType := "NUMERIC"
+		if field == "Team" || field == "Form" {
+			columnType = "TEXT"
+		}
+		columns = append(columns, field+" "+columnType)
+	}
+	statement := "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(columns, ", ") + ")"
+	if _, err := db.Exec(statement); err != nil {
+		log.Fatalln("Couldn't create the table '"+table+"' in the database", err)
+	}
+	rows, err := db.Query("SELECT name FROM pragma_table_info('" + table + "')")
+	if err != nil {
+		log.Fatalln("Couldn't read the columns of the table '"+table+"'", err)
+	}
+	defer rows.Close()
+	existingColumns := []string{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			log.Fatalln("Couldn't read the columns of the table '"+table+"'", err)
+		}
+		existingColumns = append(existingColumns, column)
+	}
+	for idx, field := range fields {
+		if !stringInSlice(field, existingColumns) {
+			if _, err := db.Exec("ALTER TABLE " + table + " ADD COLUMN " + columns[idx+3]); err != nil {
+				log.Fatalln("Couldn't add the column '"+field+"' to the table '"+table+"'", err)
+			}
+		}
+	}
+}
+
+// Gets hash of the contents of a `RawData` record, to detect records that were edited since they were imported
+func getRecordHash(record RawData) string {
+	contents := fmt.Sprintf("%v", []interface{}{
+		record.HomeTeam, record.HomeGoals, record.AwayGoals, record.AwayTeam, record.Date.Format(dateLayouts[0]),
+		record.HasHalfTime, record.HalfTimeHomeGoals, record.HalfTimeAwayGoals,
+		record.HasExtraTime, record.HomeGoalsAET, record.AwayGoalsAET,
+		record.HasShootout, record.HomePenalties, record.AwayPenalties, record.Stage,
+	})
+	hash := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(hash[:])
+}
+
+// Gets value of an optional score for the database i.e; nil (NULL) if the score isn't available
+func getOptionalScoreValue(isAvailable bool, goals int) interface{} {
+	if !isAvailable {
+		return nil
+	}
+	return goals
+}
+
+/*
+Ingests `RawData` records of a data file into the Matches table. Records are identified by data file and record
+number i.e; the line of the data file having the match, so filling in a fixture doesn't shift the records after it.
+Only new records (and records edited since they were imported) are written, and records of lines that no longer have
+a match are deleted. Goals are stored as in the data file i.e; the regulation score of matches that went to extra
+time. Returns the number of records written.
+*/
+func (store *ResultStore) ingestMatches(filename string, records []RawData) int {
+	mapHashByRecordNumber := map[int]string{}
+	rows, err := store.db.Query("SELECT RecordNumber, ContentHash FROM Matches WHERE SourceFile = ?", filename)
+	if err != nil {
+		log.Fatalln("Couldn't read matches from the database", err)
+	}
+	for rows.Next() {
+		var recordNumber int
+		var contentHash string
+		if err := rows.Scan(&recordNumber, &contentHash); err != nil {
+			log.Fatalln("Couldn't read matches from the database", err)
+		}
+		mapHashByRecordNumber[recordNumber] = contentHash
+	}
+	rows.Close()
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		log.Fatalln("Couldn't start a transaction", err)
+	}
+	statement, err := tx.Prepare(`INSERT OR REPLACE INTO Matches (
+		SourceFile, RecordNumber, ContentHash, ImportedByRunId, ImportedAt, HomeTeam, HomeGoals, AwayGoals, AwayTeam, Date,
+		HalfTimeHomeGoals, HalfTimeAwayGoals, HomeGoalsAET, AwayGoalsAET, HomePenalties, AwayPenalties, Stage
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		log.Fatalln("Couldn't prepare the statement for ingesting matches", err)
+	}
+	defer statement.Close()
+	importedAt := time.Now().UTC().Format(time.RFC3339)
+	numWritten := 0
+	isPresent := map[int]bool{}
+	for _, record := range records {
+		recordNumber, contentHash := record.LineNumber, getRecordHash(record)
+		isPresent[recordNumber] = true
+		if mapHashByRecordNumber[recordNumber] == contentHash {
+			continue
+		}
+		var date, stage interface{}
+		if !record.Date.IsZero() {
+			date = record.Date.Format(dateLayouts[0])
+		}
+		if record.Stage != "" {
+			stage = record.Stage
+		}
+		_, err := statement.Exec(
+			filename, recordNumber, contentHash, store.runId, importedAt,
+			record.HomeTeam, record.HomeGoals, record.AwayGoals, record.AwayTeam, date,
+			getOptionalScoreValue(record.HasHalfTime, record.HalfTimeHomeGoals), getOptionalScoreValue(record.HasHalfTime, record.HalfTimeAwayGoals),
+			getOptionalScoreValue(record.HasExtraTime, record.HomeGoalsAET), getOptionalScoreValue(record.HasExtraTime, record.AwayGoalsAET),
+			getOptionalScoreValue(record.HasShootout, record.HomePenalties), getOptionalScoreValue(record.HasShootout, record.AwayPenalties),
+			stage,
+		)
+		if err != nil {
+			tx.Rollback()
+			log.Fatalln("Couldn't ingest match "+strconv.Itoa(recordNumber)+" of '"+filename+"'", err)
+		}
+		numWritten++
+	}
+	for recordNumber := range mapHashByRecordNumber {
+		if isPresent[recordNumber] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM Matches WHERE SourceFile = ? AND RecordNumber = ?", filename, recordNumber); err != nil {
+			tx.Rollback()
+			log.Fatalln("Couldn't delete removed matches of '"+filename+"'", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatalln("Couldn't commit matches of '"+filename+"'", err)
+	}
+	return numWritten
+}
+
+/*
+Stores a computed table of the current run i.e; header and stringified records as saved to CSV files
+(eg: `structs.Names(&StatsAbs{})` and `ListStringifiedValues()` of each object).
+*/
+func (store *ResultStore) saveStatsTable(table string, filename string, entity string, fields []string, sliceStringifiedRecords [][]string) {
+	tx, err := store.db.Begin()
+	if err != nil {
+		log.Fatalln("Couldn't start a transaction", err)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(fields)+3), ", ")
+	statement, err := tx.Prepare("INSERT INTO " + table + " (RunId, SourceFile, Entity, " + strings.Join(fields, ", ") + ") VALUES (" + placeholders + ")")
+	if err != nil {
+		log.Fatalln("Couldn't prepare the statement for storing '"+table+"'", err)
+	}
+	defer statement.Close()
+	for _, record := range sliceStringifiedRecords {
+		values := []interface{}{store.runId, filename, entity}
+		for _, value := range record {
+			values = append(values, value)
+		}
+		if _, err := statement.Exec(values...); err != nil {
+			tx.Rollback()
+			log.Fatalln("Couldn't store '"+table+"' of '"+filename+"'", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatalln("Couldn't commit '"+table+"' of '"+filename+"'", err)
+	}
+}
+
+// Stores absolute stats, normalized stats and latest form of teams/individuals (as per `entity`) for the current run
+func (store *ResultStore) saveStats(filename string, entity string, sliceAbsStats []StatsAbs, sliceNormStats []StatsNorm, sliceLatestForm []LatestForm) {
+	absRecords, normRecords, formRecords := [][]string{}, [][]string{}, [][]string{}
+	for _, obj := range sliceAbsStats {
+		absRecords = append(absRecords, obj.ListStringifiedValues())
+	}
+	for _, obj := range sliceNormStats {
+		normRecords = append(normRecords, obj.ListStringifiedValues())
+	}
+	for _, obj := range sliceLatestForm {
+		formRecords = append(formRecords, obj.ListStringifiedValues())
+	}
+	store.saveStatsTable("AbsoluteStats", filename, entity, structs.Names(&StatsAbs{}), absRecords)
+	store.saveStatsTable("NormalizedStats", filename, entity, structs.Names(&StatsNorm{}), normRecords)
+	store.saveStatsTable("LatestForm", filename, entity, structs.Names(&LatestForm{}), formRecords)
+}
+
+func (store *ResultStore) close() {
+	store.db.Close()
+}
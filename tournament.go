@@ -0,0 +1,355 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/structs"
+)
+
+// Struct to store a team's standing in its group, along with whether it qualified for the knockout stage
+type GroupStanding struct {
+	Group     string
+	Stats     StatsAbs
+	Qualified bool
+}
+
+/*
+Method that gets slice of stringified elements of `GroupStanding` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the columns given by `getGroupStandingFields`.
+Used as helper function in storing data of `GroupStanding` struct to CSV file.
+*/
+func (obj GroupStanding) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Group)
+	values = append(values, obj.Stats.ListStringifiedValues()...)
+	values = append(values, strconv.FormatBool(obj.Qualified))
+	return values
+}
+
+// Gets column names of group tables i.e; "Group", followed by the attributes of `StatsAbs` and "Qualified"
+func getGroupStandingFields() []string {
+	fields := []string{"Group"}
+	fields = append(fields, structs.Names(&StatsAbs{})...)
+	fields = append(fields, "Qualified")
+	return fields
+}
+
+// Struct to store a qualifier for the knockout stage. Qualifiers are seeded by their order
+type Qualifier struct {
+	Team  string
+	Slot  string // Eg: "1st Group A"
+	Stats StatsAbs
+}
+
+// Struct to store a match of the tournament bracket. Teams are empty if yet to be decided
+type BracketMatch struct {
+	Round     int
+	RoundName string // Eg: "Quarter-finals", "Final"
+	Match     int    // Number of the match within its round
+	HomeSlot  string // Eg: "1st Group A", "Winner of Round 1 Match 2"
+	HomeTeam  string
+	AwaySlot  string
+	AwayTeam  string
+	Score     string
+	Winner    string
+}
+
+/*
+Method that gets slice of stringified elements of `BracketMatch` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `BracketMatch` struct to CSV file.
+*/
+func (obj BracketMatch) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, strconv.Itoa(obj.Round))
+	values = append(values, obj.RoundName)
+	values = append(values, strconv.Itoa(obj.Match))
+	values = append(values, obj.HomeSlot)
+	values = append(values, obj.HomeTeam)
+	values = append(values, obj.AwaySlot)
+	values = append(values, obj.AwayTeam)
+	values = append(values, obj.Score)
+	values = append(values, obj.Winner)
+	return values
+}
+
+// Returns true if any of the `RawData` records belongs to a group stage
+func hasGroupStages(records []RawData) bool {
+	for _, record := range records {
+		if isGroupStage(record.Stage) {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets `RawData` records of group stages, by group (in order of appearance)
+func getRecordsByGroup(records []RawData) ([]string, map[string][]RawData) {
+	groups := []string{}
+	mapRecordsByGroup := map[string][]RawData{}
+	for _, record := range records {
+		if !isGroupStage(record.Stage) {
+			continue
+		}
+		if _, ok := mapRecordsByGroup[record.Stage]; !ok {
+			groups = append(groups, record.Stage)
+		}
+		mapRecordsByGroup[record.Stage] = append(mapRecordsByGroup[record.Stage], record)
+	}
+	return groups, mapRecordsByGroup
+}
+
+// Gets ordinal of a position i.e; 1 -> "1st", 2 -> "2nd", 11 -> "11th"
+func getOrdinal(position int) string {
+	suffix := "th"
+	if position%100 < 11 || position%100 > 13 {
+		switch position % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(position) + suffix
+}
+
+/*
+Gets group tables from `RawData` records (ranked by the same metric as absolute stats), and the qualifiers for the
+knockout stage. The top `qualifiersPerGroup` teams of each group qualify, along with the best `bestNextPlaced` teams
+placed just below them (eg: best third-placed teams). Qualifiers are seeded by position, then by the ranking metric.
+*/
+func getGroupStandings(records []RawData, qualifiersPerGroup int, bestNextPlaced int, rules ScoringRules) ([]GroupStanding, []Qualifier) {
+	groups, mapRecordsByGroup := getRecordsByGroup(records)
+	sliceGroupStandings := []GroupStanding{}
+	mapQualifiersByPosition := map[int][]Qualifier{}
+	for _, group := range groups {
+		sliceAbsStats := getAbsoluteStats(mapRecordsByGroup[group], rules)
+		sliceAbsStats = sortAbsStatsByMetric(sliceAbsStats)
+		sliceAbsStats = attachRankingToAbsStats(sliceAbsStats)
+		for _, stats := range sliceAbsStats {
+			if stats.Rank <= qualifiersPerGroup+1 {
+				tempObj := Qualifier{Team: stats.Team, Slot: getOrdinal(stats.Rank) + " " + group, Stats: stats}
+				mapQualifiersByPosition[stats.Rank] = append(mapQualifiersByPosition[stats.Rank], tempObj)
+			}
+			sliceGroupStandings = append(sliceGroupStandings, GroupStanding{Group: group, Stats: stats})
+		}
+	}
+	sliceQualifiers := []Qualifier{}
+	for position := 1; position <= qualifiersPerGroup+1; position++ {
+		sliceQualifiersAtPosition := mapQualifiersByPosition[position]
+		sort.SliceStable(sliceQualifiersAtPosition, func(i, j int) bool {
+			statsOfI, statsOfJ := sliceQualifiersAtPosition[i].Stats, sliceQualifiersAtPosition[j].Stats
+			ppgOfI := float64(statsOfI.Points) / float64(statsOfI.GamesPlayed)
+			ppgOfJ := float64(statsOfJ.Points) / float64(statsOfJ.GamesPlayed)
+			return ppgOfI > ppgOfJ
+		})
+		if position == qualifiersPerGroup+1 && len(sliceQualifiersAtPosition) > bestNextPlaced {
+			sliceQualifiersAtPosition = sliceQualifiersAtPosition[:bestNextPlaced]
+		}
+		sliceQualifiers = append(sliceQualifiers, sliceQualifiersAtPosition...)
+	}
+	mapQualifiedTeams := map[string]bool{}
+	for _, qualifier := range sliceQualifiers {
+		mapQualifiedTeams[qualifier.Team] = true
+	}
+	for idx := range sliceGroupStandings {
+		sliceGroupStandings[idx].Qualified = mapQualifiedTeams[sliceGroupStandings[idx].Stats.Team]
+	}
+	return sliceGroupStandings, sliceQualifiers
+}
+
+/*
+Gets order of seeds in the first round of a bracket of size `bracketSize` (a power of 2), such that the top seeds
+can only meet in the latest rounds. Eg: 8 -> [1 8 4 5 2 7 3 6] i.e; 1 v 8, 4 v 5, 2 v 7 and 3 v 6
+*/
+func getSeedOrder(bracketSize int) []int {
+	seedOrder := []int{1}
+	for size := 2; size <= bracketSize; size *= 2 {
+		nextSeedOrder := []int{}
+		for _, seed := range seedOrder {
+			nextSeedOrder = append(nextSeedOrder, seed, size+1-seed)
+		}
+		seedOrder = nextSeedOrder
+	}
+	return seedOrder
+}
+
+// Gets name of a round, given the number of rounds in the bracket. Eg: "Final", "Semi-finals", "Round of 16"
+func getRoundName(round int, numRounds int) string {
+	switch numRounds - round {
+	case 0:
+		return "Final"
+	case 1:
+		return "Semi-finals"
+	case 2:
+		return "Quarter-finals"
+	}
+	return "Round of " + strconv.Itoa(1<<uint(numRounds-round+1))
+}
+
+/*
+Finds the first knockout match (of `RawData` records) between two teams that hasn't been used yet.
+Returns index of the match, or -1 if the teams haven't played each other yet.
+*/
+func findKnockoutMatch(knockoutRecords []RawData, teamA string, teamB string, mapUsedMatches map[int]bool) int {
+	for idx, record := range knockoutRecords {
+		if mapUsedMatches[idx] {
+			continue
+		}
+		if (record.HomeTeam == teamA && record.AwayTeam == teamB) || (record.HomeTeam == teamB && record.AwayTeam == teamA) {
+			return idx
+		}
+	}
+	return -1
+}
+
+/*
+Gets tournament bracket for the qualifiers, filled in from knockout results of `RawData` records. Qualifiers are
+placed by seed, and top seeds get byes if the number of qualifiers isn't a power of 2.
+Returns slice wherein each element of the slice is an object of the struct `BracketMatch`
+*/
+func getTournamentBracket(records []RawData, sliceQualifiers []Qualifier) []BracketMatch {
+	sliceBracketMatches := []BracketMatch{}
+	if len(sliceQualifiers) < 2 {
+		return sliceBracketMatches
+	}
+	bracketSize, numRounds := 1, 0
+	for bracketSize < len(sliceQualifiers) {
+		bracketSize *= 2
+		numRounds++
+	}
+	knockoutRecords := getKnockoutRecords(records)
+	mapUsedMatches := map[int]bool{}
+	seedOrder := getSeedOrder(bracketSize)
+	previousRound := []BracketMatch{}
+	for round := 1; round <= numRounds; round++ {
+		currentRound := []BracketMatch{}
+		numMatches := bracketSize >> uint(round)
+		for idx := 0; idx < numMatches; idx++ {
+			tempObj := BracketMatch{Round: round, RoundName: getRoundName(round, numRounds), Match: idx + 1}
+			if round == 1 {
+				for side, seed := range []int{seedOrder[2*idx], seedOrder[2*idx+1]} {
+					slot, team := "Bye", ""
+					if seed <= len(sliceQualifiers) {
+						slot, team = sliceQualifiers[seed-1].Slot, sliceQualifiers[seed-1].Team
+					}
+					if side == 0 {
+						tempObj.HomeSlot, tempObj.HomeTeam = slot, team
+					} else {
+						tempObj.AwaySlot, tempObj.AwayTeam = slot, team
+					}
+				}
+			} else {
+				tempObj.HomeSlot = "Winner of Round " + strconv.Itoa(round-1) + " Match " + strconv.Itoa(2*idx+1)
+				tempObj.HomeTeam = previousRound[2*idx].Winner
+				tempObj.AwaySlot = "Winner of Round " + strconv.Itoa(round-1) + " Match " + strconv.Itoa(2*idx+2)
+				tempObj.AwayTeam = previousRound[2*idx+1].Winner
+			}
+			if tempObj.HomeSlot == "Bye" || tempObj.AwaySlot == "Bye" {
+				tempObj.Score = "Bye"
+				tempObj.Winner = tempObj.HomeTeam + tempObj.AwayTeam // Only one of them is non-empty
+			} else if tempObj.HomeTeam != "" && tempObj.AwayTeam != "" {
+				idxMatch := findKnockoutMatch(knockoutRecords, tempObj.HomeTeam, tempObj.AwayTeam, mapUsedMatches)
+				if idxMatch != -1 {
+					mapUsedMatches[idxMatch] = true
+					record := knockoutRecords[idxMatch]
+					if record.HomeTeam != tempObj.HomeTeam {
+						tempObj.HomeSlot, tempObj.AwaySlot = tempObj.AwaySlot, tempObj.HomeSlot
+					}
+					tempObj.HomeTeam, tempObj.AwayTeam = record.HomeTeam, record.AwayTeam
+					tempObj.Score = getScoreline(record)
+					tempObj.Winner = getMatchWinner(record)
+				}
+			}
+			currentRound = append(currentRound, tempObj)
+		}
+		sliceBracketMatches = append(sliceBracketMatches, currentRound...)
+		previousRound = currentRound
+	}
+	return sliceBracketMatches
+}
+
+// Saves slice having objects of `GroupStanding` struct to CSV file
+func saveGroupStandingsToCsv(sliceData []GroupStanding, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	sliceStringifiedRecords = append(sliceStringifiedRecords, getGroupStandingFields())
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `BracketMatch` struct to CSV file
+func saveTournamentBracketToCsv(sliceData []BracketMatch, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&BracketMatch{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Template of the HTML page of a tournament bracket, having a column per round
+const tournamentBracketTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+.bracket { display: flex; }
+.round { display: flex; flex-direction: column; justify-content: space-around; margin-right: 24px; min-width: 220px; }
+.match { border: 1px solid #999; margin: 8px 0; }
+.side { display: flex; justify-content: space-between; padding: 4px 8px; }
+.winner { font-weight: bold; }
+.slot { color: #777; font-size: 0.8em; padding: 0 8px; }
+.score { background: #eee; font-size: 0.8em; padding: 2px 8px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="bracket">
+{{range .Rounds}}<div class="round">
+<h3>{{(index . 0).RoundName}}</h3>
+{{range .}}<div class="match">
+<div class="side{{if and .HomeTeam (eq .HomeTeam .Winner)}} winner{{end}}">{{if .HomeTeam}}{{.HomeTeam}}{{else}}TBD{{end}}</div>
+<div class="slot">{{.HomeSlot}}</div>
+<div class="side{{if and .AwayTeam (eq .AwayTeam .Winner)}} winner{{end}}">{{if .AwayTeam}}{{.AwayTeam}}{{else}}TBD{{end}}</div>
+<div class="slot">{{.AwaySlot}}</div>
+{{if .Score}}<div class="score">{{.Score}}</div>{{end}}
+</div>
+{{end}}</div>
+{{end}}</div>
+</body>
+</html>
+`
+
+// Saves slice having objects of `BracketMatch` struct to HTML file, having a column per round
+func saveTournamentBracketToHtml(sliceData []BracketMatch, title string, filepath string) {
+	rounds := [][]BracketMatch{}
+	for _, obj := range sliceData {
+		if obj.Round > len(rounds) {
+			rounds = append(rounds, []BracketMatch{})
+		}
+		rounds[obj.Round-1] = append(rounds[obj.Round-1], obj)
+	}
+	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0777)
+	if err != nil {
+		log.Fatalln("Couldn't create the HTML file", err)
+	}
+	defer file.Close()
+	tmpl := template.Must(template.New("bracket").Parse(tournamentBracketTemplate))
+	err = tmpl.Execute(file, map[string]interface{}{"Title": title, "Rounds": rounds})
+	if err != nil {
+		log.Fatalln("Couldn't write the HTML file", err)
+	}
+}
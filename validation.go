@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// Constants - Severity levels of validation issues
+const (
+	severityError   = "error"   // Results can't be trusted. Stats aren't computed
+	severityWarning = "warning" // Data is suspicious. Stats are computed, unless failing on warnings
+)
+
+// Constants - Names of validation checks
+const (
+	checkHomeSameAsAway     = "HomeSameAsAway"
+	checkInvalid2v2Naming   = "Invalid2v2Naming"
+	checkNegativeGoals      = "NegativeGoals"
+	checkAbsurdScore        = "AbsurdScore"
+	checkHalfTimeScore      = "HalfTimeScore"
+	checkExtraTimeScore     = "ExtraTimeScore"
+	checkShootoutScore      = "ShootoutScore"
+	checkDuplicateFixture   = "DuplicateFixture"
+	checkPlayedTwiceOnDate  = "PlayedTwiceOnDate"
+	checkUnbalancedHomeAway = "UnbalancedHomeAway"
+	checkTooFewGames        = "TooFewGames"
+	checkNameWhitespace     = "NameWhitespace"
+	checkNameVariant        = "NameVariant"
+)
+
+// Struct to store an issue found while validating raw data
+type ValidationIssue struct {
+	Severity     string
+	Check        string
+	RecordNumber int // Line of the data file having the record the issue is about (1 being the header). 0 if not about a single record
+	Team         string
+	Message      string
+}
+
+/*
+Method that gets slice of stringified elements of `ValidationIssue` struct (by record).
+NOTE: Elements of the slice returned must be in same order as the attributes defined in the struct.
+Used as helper function in storing data of `ValidationIssue` struct to CSV file.
+*/
+func (obj ValidationIssue) ListStringifiedValues() []string {
+	values := []string{}
+	values = append(values, obj.Severity)
+	values = append(values, obj.Check)
+	values = append(values, strconv.Itoa(obj.RecordNumber))
+	values = append(values, obj.Team)
+	values = append(values, obj.Message)
+	return values
+}
+
+// Describes a match i.e; "Arsenal 1-1 (2-2 aet) Chelsea"
+func describeRecord(record RawData) string {
+	return record.HomeTeam + " " + getScoreline(record) + " " + record.AwayTeam
+}
+
+// Checks if `HomeTeam` name is same as `AwayTeam` name
+func checkRecordsForHomeSameAsAway(records []RawData) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, record := range records {
+		if record.HomeTeam == record.AwayTeam {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityError,
+				Check:        checkHomeSameAsAway,
+				RecordNumber: record.LineNumber,
+				Team:         record.HomeTeam,
+				Message:      "HomeTeam is same as AwayTeam. Team-names given: " + record.HomeTeam + ", " + record.AwayTeam,
+			})
+		}
+	}
+	return issues
+}
+
+// NOTE: Used for 2v2 games only. Checks if team-names follow the 2v2 naming convention
+func checkRecordsFor2v2Naming(records []RawData) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, record := range records {
+		if !isValid2v2Naming([]RawData{record}) {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityError,
+				Check:        checkInvalid2v2Naming,
+				RecordNumber: record.LineNumber,
+				Message:      "Incorrect 2v2 naming convention. Team-names given: " + record.HomeTeam + ", " + record.AwayTeam,
+			})
+		}
+	}
+	return issues
+}
+
+/*
+Checks for negative goals, for absurdly high goals (more than `maxGoals` by one side),
+for half-time goals that are negative or exceed full-time goals, for extra-time goals that are fewer than
+regulation goals, and for penalty shootouts that are drawn or follow a decisive score.
+*/
+func checkRecordsForScores(records []RawData, maxGoals int) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, record := range records {
+		if record.HomeGoals < 0 || record.AwayGoals < 0 {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityError,
+				Check:        checkNegativeGoals,
+				RecordNumber: record.LineNumber,
+				Message:      "Negative goals in " + describeRecord(record),
+			})
+		} else if record.HomeGoals > maxGoals || record.AwayGoals > maxGoals {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityWarning,
+				Check:        checkAbsurdScore,
+				RecordNumber: record.LineNumber,
+				Message:      "More than " + strconv.Itoa(maxGoals) + " goals by one side in " + describeRecord(record),
+			})
+		}
+		regulationHome, regulationAway := record.HomeGoals, record.AwayGoals
+		if record.HasHalfTime {
+			htHome, htAway := record.HalfTimeHomeGoals, record.HalfTimeAwayGoals
+			if htHome < 0 || htAway < 0 || htHome > regulationHome || htAway > regulationAway {
+				issues = append(issues, ValidationIssue{
+					Severity:     severityError,
+					Check:        checkHalfTimeScore,
+					RecordNumber: record.LineNumber,
+					Message:      "Half-time score " + strconv.Itoa(htHome) + "-" + strconv.Itoa(htAway) + " is inconsistent with " + describeRecord(record),
+				})
+			}
+		}
+		if record.HasExtraTime && (record.HomeGoalsAET < regulationHome || record.AwayGoalsAET < regulationAway) {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityError,
+				Check:        checkExtraTimeScore,
+				RecordNumber: record.LineNumber,
+				Message:      "Score after extra time " + strconv.Itoa(record.HomeGoalsAET) + "-" + strconv.Itoa(record.AwayGoalsAET) + " is inconsistent with " + describeRecord(record),
+			})
+		}
+		if record.HasShootout {
+			homeGoals, awayGoals := getScoreAfterExtraTime(record)
+			if homeGoals != awayGoals || record.HomePenalties == record.AwayPenalties || record.HomePenalties < 0 || record.AwayPenalties < 0 {
+				issues = append(issues, ValidationIssue{
+					Severity:     severityError,
+					Check:        checkShootoutScore,
+					RecordNumber: record.LineNumber,
+					Message:      "Penalty shootout " + strconv.Itoa(record.HomePenalties) + "-" + strconv.Itoa(record.AwayPenalties) + " is inconsistent with " + describeRecord(record),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+/*
+Checks for duplicate fixtures. With dates, a fixture is a duplicate if the same teams meet at the same venue on the same date.
+Without dates, a league (non-2v2) fixture is a duplicate if the same teams meet at the same venue more than once.
+Repeated 2v2 pairings without dates are normal, so they aren't checked.
+*/
+func checkRecordsForDuplicateFixtures(records []RawData, is2v2 bool) []ValidationIssue {
+	issues := []ValidationIssue{}
+	mapFirstRecordNumberByFixture := map[string]int{}
+	for _, record := range records {
+		if record.Date.IsZero() && is2v2 {
+			continue
+		}
+		fixture := record.HomeTeam + " vs " + record.AwayTeam
+		if !record.Date.IsZero() {
+			fixture += " on " + record.Date.Format("2006-01-02")
+		}
+		if firstRecordNumber, ok := mapFirstRecordNumberByFixture[fixture]; ok {
+			issues = append(issues, ValidationIssue{
+				Severity:     severityWarning,
+				Check:        checkDuplicateFixture,
+				RecordNumber: record.LineNumber,
+				Message:      "Fixture " + fixture + " already appears at line " + strconv.Itoa(firstRecordNumber),
+			})
+		} else {
+			mapFirstRecordNumberByFixture[fixture] = record.LineNumber
+		}
+	}
+	return issues
+}
+
+// NOTE: Used for non-2v2 games only (2v2 nights have many games per date). Checks for teams playing twice on the same date
+func checkRecordsForPlayedTwiceOnDate(records []RawData) []ValidationIssue {
+	issues := []ValidationIssue{}
+	mapRecordNumberByTeamDate := map[string]int{}
+	for _, record := range records {
+		if record.Date.IsZero() {
+			continue
+		}
+		date := record.Date.Format("2006-01-02")
+		for _, team := range []string{record.HomeTeam, record.AwayTeam} {
+			key := team + " on " + date
+			if firstRecordNumber, ok := mapRecordNumberByTeamDate[key]; ok {
+				issues = append(issues, ValidationIssue{
+					Severity:     severityWarning,
+					Check:        checkPlayedTwiceOnDate,
+					RecordNumber: record.LineNumber,
+					Team:         team,
+					Message:      team + " also plays on " + date + " at line " + strconv.Itoa(firstRecordNumber),
+				})
+			} else {
+				mapRecordNumberByTeamDate[key] = record.LineNumber
+			}
+		}
+	}
+	return issues
+}
+
+/*
+NOTE: Used for non-2v2 games only. Checks for teams whose home and away games differ by more than 1, as expected in a
+double round-robin. Only league matches are checked (see `getLeagueRecords`), since knockout ties needn't be balanced.
+*/
+func checkRecordsForHomeAwayBalance(records []RawData) []ValidationIssue {
+	issues := []ValidationIssue{}
+	leagueRecords := getLeagueRecords(records)
+	mapHomeCountByTeam, mapAwayCountByTeam := map[string]int{}, map[string]int{}
+	for _, record := range leagueRecords {
+		mapHomeCountByTeam[record.HomeTeam]++
+		mapAwayCountByTeam[record.AwayTeam]++
+	}
+	for _, team := range getUniqueTeamNames(leagueRecords) {
+		homeCount, awayCount := mapHomeCountByTeam[team], mapAwayCountByTeam[team]
+		if homeCount-awayCount > 1 || awayCount-homeCount > 1 {
+			issues = append(issues, ValidationIssue{
+				Severity: severityWarning,
+				Check:    checkUnbalancedHomeAway,
+				Team:     team,
+				Message:  team + " has " + strconv.Itoa(homeCount) + " home and " + strconv.Itoa(awayCount) + " away games",
+			})
+		}
+	}
+	return issues
+}
+
+/*
+Checks for teams having played fewer than `minGamesPlayed` games.
+If `minGamesPlayed` isn't positive, a quarter of the median games played is used instead.
+*/
+func checkRecordsForTooFewGames(records []RawData, minGamesPlayed int) []ValidationIssue {
+	issues := []ValidationIssue{}
+	teams := getUniqueTeamNames(records)
+	mapGamesPlayedByTeam := map[string]int{}
+	sliceGamesPlayed := []int{}
+	for _, team := range teams {
+		gamesPlayed := getGamesPlayedCount(records, team)
+		mapGamesPlayedByTeam[team] = gamesPlayed
+		sliceGamesPlayed = append(sliceGamesPlayed, gamesPlayed)
+	}
+	if minGamesPlayed <= 0 && len(sliceGamesPlayed) > 0 {
+		sort.Ints(sliceGamesPlayed)
+		minGamesPlayed = sliceGamesPlayed[len(sliceGamesPlayed)/2] / 4
+	}
+	for _, team := range teams {
+		if mapGamesPlayedByTeam[team] < minGamesPlayed {
+			issues = append(issues, ValidationIssue{
+				Severity: severityWarning,
+				Check:    checkTooFewGames,
+				Team:     team,
+				Message:  team + " has played " + strconv.Itoa(mapGamesPlayedByTeam[team]) + " games, fewer than " + strconv.Itoa(minGamesPlayed),
+			})
+		}
+	}
+	return issues
+}
+
+/*
+Checks team-names for leading/trailing/repeated whitespace, and for names that differ only by case or whitespace
+(i.e; "Man City" vs "man city ") which would otherwise be treated as different teams.
+*/
+func checkRecordsForNameVariants(records []RawData) []ValidationIssue {
+	issues := []ValidationIssue{}
+	mapNamesByNormalisedName := map[string][]string{}
+	teams := getUniqueTeamNames(records)
+	for _, team := range teams {
+		normalisedName := strings.Join(strings.Fields(team), " ")
+		if normalisedName != team {
+			issues = append(issues, ValidationIssue{
+				Severity: severityWarning,
+				Check:    checkNameWhitespace,
+				Team:     team,
+				Message:  "Team-name '" + team + "' has leading, trailing or repeated whitespace",
+			})
+		}
+		key := strings.ToLower(normalisedName)
+		mapNamesByNormalisedName[key] = append(mapNamesByNormalisedName[key], team)
+	}
+	for _, team := range teams {
+		variants := mapNamesByNormalisedName[strings.ToLower(strings.Join(strings.Fields(team), " "))]
+		if len(variants) > 1 && variants[0] == team {
+			issues = append(issues, ValidationIssue{
+				Severity: severityWarning,
+				Check:    checkNameVariant,
+				Team:     team,
+				Message:  "Team-names differ only by case or whitespace: '" + strings.Join(variants, "', '") + "'",
+			})
+		}
+	}
+	return issues
+}
+
+// Validates `RawData` records of a data file, and returns all issues found
+func validateRecords(records []RawData, filename string, options PipelineOptions) []ValidationIssue {
+	is2v2 := filenameContains2v2(filename)
+	issues := []ValidationIssue{}
+	issues = append(issues, checkRecordsForHomeSameAsAway(records)...)
+	if is2v2 {
+		issues = append(issues, checkRecordsFor2v2Naming(records)...)
+	}
+	issues = append(issues, checkRecordsForScores(records, options.MaxGoals)...)
+	issues = append(issues, checkRecordsForDuplicateFixtures(records, is2v2)...)
+	if !is2v2 {
+		issues = append(issues, checkRecordsForPlayedTwiceOnDate(records)...)
+		issues = append(issues, checkRecordsForHomeAwayBalance(records)...)
+	}
+	issues = append(issues, checkRecordsForTooFewGames(records, options.MinGamesPlayed)...)
+	issues = append(issues, checkRecordsForNameVariants(records)...)
+	return issues
+}
+
+// Gets count of issues having the given severity
+func countIssuesBySeverity(issues []ValidationIssue, severity string) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+/*
+Returns true if the issues should stop stats from being computed i.e; any error, or any warning when failing on warnings.
+Invalid 2v2 naming is ignored here, since it only stops individuals' stats from being computed.
+*/
+func hasBlockingIssues(issues []ValidationIssue, failOnWarnings bool) bool {
+	for _, issue := range issues {
+		if issue.Check == checkInvalid2v2Naming {
+			continue
+		}
+		if issue.Severity == severityError || (failOnWarnings && issue.Severity == severityWarning) {
+			return true
+		}
+	}
+	return false
+}
+
+// Saves slice having objects of `ValidationIssue` struct to CSV file
+func saveValidationIssuesToCsv(sliceData []ValidationIssue, filepath string) {
+	sliceStringifiedRecords := [][]string{} // Slice of slice of strings, where each sub-slice represents a record
+	statFields := structs.Names(&ValidationIssue{})
+	sliceStringifiedRecords = append(sliceStringifiedRecords, statFields)
+	for _, obj := range sliceData {
+		record := obj.ListStringifiedValues()
+		sliceStringifiedRecords = append(sliceStringifiedRecords, record)
+	}
+	writeStringifiedRecordsToCsv(sliceStringifiedRecords, filepath)
+}
+
+// Saves slice having objects of `ValidationIssue` struct to JSON file
+func saveValidationIssuesToJson(sliceData []ValidationIssue, filepath string) {
+	content, err := json.MarshalIndent(sliceData, "", "  ")
+	if err != nil {
+		log.Fatalln("Couldn't encode the validation report", err)
+	}
+	if err := os.WriteFile(filepath, content, 0777); err != nil {
+		log.Fatalln("Couldn't create the JSON file", err)
+	}
+}
+
+/*
+Validates `RawData` records of a data file, saves the validation report (CSV/JSON as per `PipelineOptions`) and prints a summary.
+Returns true if the records are fit for computing stats.
+*/
+func executeValidation(records []RawData, filename string, options PipelineOptions) bool {
+	issues := validateRecords(records, filename, options)
+	pathReport := pathResultsFolder + "/" + removeExtension(filename) + " - Validation." + options.ValidationFormat
+	if options.ValidationFormat == "json" {
+		saveValidationIssuesToJson(issues, pathReport)
+	} else {
+		saveValidationIssuesToCsv(issues, pathReport)
+	}
+	numErrors := countIssuesBySeverity(issues, severityError)
+	numWarnings := countIssuesBySeverity(issues, severityWarning)
+	if numErrors > 0 || numWarnings > 0 {
+		fmt.Println("Validation of '" + filename + "' found " + strconv.Itoa(numErrors) + " error/s and " + strconv.Itoa(numWarnings) + " warning/s. See '" + pathReport + "'")
+	}
+	if hasBlockingIssues(issues, options.FailOnWarnings) {
+		fmt.Println("Incorrect data for '" + filename + "'. Check your data!")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidationIssuesPointAtLinesOfTheDataFile(t *testing.T) {
+	filepath := t.TempDir() + "/League.csv"
+	content := "HomeTeam,HomeGoals,AwayGoals,AwayTeam\n" +
+		"Arsenal,2,1,Chelsea\n" +
+		"Chelsea,,,Everton\n" + // Fixture yet to be played, left out of the records
+		"Everton,-1,0,Arsenal\n" +
+		"Arsenal,1,1,Chelsea\n"
+	if err := os.WriteFile(filepath, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	records, err := parseRawRecordsFromCsv(filepath, readStatColumns(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapLineNumberByCheck := map[string]int{}
+	for _, issue := range validateRecords(records, "League.csv", PipelineOptions{MaxGoals: 10}) {
+		mapLineNumberByCheck[issue.Check] = issue.RecordNumber
+	}
+	if got := mapLineNumberByCheck[checkNegativeGoals]; got != 4 {
+		t.Errorf("negative goals: got line %d, want 4", got)
+	}
+	if got := mapLineNumberByCheck[checkDuplicateFixture]; got != 5 {
+		t.Errorf("duplicate fixture: got line %d, want 5", got)
+	}
+}
+
+func TestCheckRecordsForHomeAwayBalanceLeavesOutKnockoutMatches(t *testing.T) {
+	groupStage := []RawData{
+		{HomeTeam: "Arsenal", AwayTeam: "Chelsea", Stage: "Group A"},
+		{HomeTeam: "Chelsea", AwayTeam: "Arsenal", Stage: "Group A"},
+	}
+	knockoutStage := []RawData{
+		{HomeTeam: "Arsenal", AwayTeam: "Everton", Stage: "Semi-final"},
+		{HomeTeam: "Arsenal", AwayTeam: "Fulham", Stage: "Final"},
+	}
+	if issues := checkRecordsForHomeAwayBalance(append(append([]RawData{}, groupStage...), knockoutStage...)); len(issues) != 0 {
+		t.Errorf("group and knockout stages: got %+v, want no issues", issues)
+	}
+	// Without stages, matches going to extra time make it a knockout data file
+	cup := []RawData{
+		{HomeTeam: "Arsenal", AwayTeam: "Everton"},
+		{HomeTeam: "Arsenal", AwayTeam: "Chelsea", HasExtraTime: true},
+		{HomeTeam: "Arsenal", AwayTeam: "Fulham"},
+	}
+	if issues := checkRecordsForHomeAwayBalance(cup); len(issues) != 0 {
+		t.Errorf("cup without stages: got %+v, want no issues", issues)
+	}
+	// Same matches, played as a league
+	league := []RawData{cup[0], {HomeTeam: "Arsenal", AwayTeam: "Chelsea"}, cup[2]}
+	if issues := checkRecordsForHomeAwayBalance(league); len(issues) != 1 || issues[0].Team != "Arsenal" {
+		t.Errorf("league: got %+v, want an issue about Arsenal", issues)
+	}
+}
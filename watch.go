@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Struct to store the state of a data file as last seen by the watcher
+type WatchedFile struct {
+	ModTime             time.Time
+	Size                int64
+	ChangedAt           time.Time             // When a change was first seen that hasn't been computed yet. Zero if none
+	MapTableEntryByTeam map[string]TableEntry // Standings of teams as per the previous computation
+}
+
+/*
+Reruns the pipeline of a data file, and prints the changes in the table since the previous computation (if any),
+the same way as the "diff" command. Standings are kept from the previous computation if the data file can't be read
+(i.e; a cell that isn't a number), or fails validation. The watcher keeps running in either case, so the data file
+can be fixed and saved again.
+*/
+func recomputeWatchedFile(filename string, watchedFile *WatchedFile, options PipelineOptions, store *ResultStore) {
+	fmt.Println("\n[" + time.Now().Format("15:04:05") + "] Recomputing '" + filename + "'")
+	rawRecords, err := tryLoadRawRecords(pathDataFolder+"/"+filename, options)
+	if err != nil {
+		fmt.Println("Couldn't read '"+filename+"' (fix it and save again):", err)
+		return
+	}
+	if !executePipeline(filename, options, store) {
+		return
+	}
+	mapTableEntryByTeam := getTableEntries(rawRecords, false, options.ScoringRules)
+	if watchedFile.MapTableEntryByTeam != nil {
+		fmt.Println("Changes in the table of '" + filename + "':")
+		sliceTableDiffs := removeUnchangedTableDiffs(getTableDiffs(watchedFile.MapTableEntryByTeam, mapTableEntryByTeam))
+		if len(sliceTableDiffs) == 0 {
+			fmt.Println("  No changes in the table")
+		}
+		printTableDiffs(sliceTableDiffs)
+	}
+	watchedFile.MapTableEntryByTeam = mapTableEntryByTeam
+}
+
+/*
+Command that watches the data folder (by polling), and reruns the pipeline of a data file once it has changed.
+A change is computed only once the data file hasn't changed for the debounce period, so rapid saves are computed once.
+Data files locked by a match being added are computed once the lock is released.
+*/
+func runWatchCommand(args []string) {
+	flagSet := flag.NewFlagSet("watch", flag.ExitOnError)
+	options := registerPipelineFlags(flagSet)
+	interval := flagSet.Duration("interval", time.Second, "How often the data folder is polled for changes")
+	debounce := flagSet.Duration("debounce", 2*time.Second, "How long a data file must be left unchanged before it's recomputed")
+	flagSet.Parse(args)
+	checkPipelineOptions(*options)
+
+	var store *ResultStore
+	if options.DatabasePath != "" {
+		store = openResultStore(options.DatabasePath, *options)
+	}
+	mapWatchedFileByName := map[string]*WatchedFile{} // Data files are computed once the debounce period has passed at start
+	fmt.Println("Watching '" + pathDataFolder + "' for changes (press Ctrl+C to stop)")
+	for {
+		filenames := getListOfDataFilenames()
+		isPresent := map[string]bool{}
+		for _, filename := range filenames {
+			isPresent[filename] = true
+			fileInfo, err := os.Stat(pathDataFolder + "/" + filename)
+			if err != nil {
+				continue // Removed since listing
+			}
+			watchedFile, ok := mapWatchedFileByName[filename]
+			if !ok {
+				watchedFile = &WatchedFile{}
+				mapWatchedFileByName[filename] = watchedFile
+			}
+			if !fileInfo.ModTime().Equal(watchedFile.ModTime) || fileInfo.Size() != watchedFile.Size {
+				watchedFile.ModTime, watchedFile.Size = fileInfo.ModTime(), fileInfo.Size()
+				watchedFile.ChangedAt = time.Now()
+			}
+			if _, err := os.Stat(pathDataFolder + "/" + filename + ".lock"); err == nil {
+				continue
+			}
+			if !watchedFile.ChangedAt.IsZero() && time.Since(watchedFile.ChangedAt) >= *debounce {
+				watchedFile.ChangedAt = time.Time{}
+				recomputeWatchedFile(filename, watchedFile, *options, store)
+			}
+		}
+		for filename := range mapWatchedFileByName {
+			if !isPresent[filename] {
+				fmt.Println("\n'" + filename + "' was removed")
+				delete(mapWatchedFileByName, filename)
+			}
+		}
+		time.Sleep(*interval)
+	}
+}